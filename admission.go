@@ -0,0 +1,91 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	rateWindow = 100 * time.Millisecond // 吞吐采样窗口
+	rateAlpha  = 0.3                    // EWMA 平滑系数
+)
+
+// rateMeter 以 EWMA 估算事件速率（每秒事件数）
+// Mark 只做一次原子加，窗口滚动时才加锁
+type rateMeter struct {
+	count atomic.Int64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	rate        float64
+}
+
+func newRateMeter() *rateMeter {
+	return &rateMeter{windowStart: time.Now()}
+}
+
+// Mark 记录一次事件
+func (m *rateMeter) Mark() {
+	m.count.Add(1)
+	m.roll(time.Now())
+}
+
+// Rate 返回平滑后的每秒事件数，长时间没有事件时逐窗口衰减
+func (m *rateMeter) Rate() float64 {
+	m.roll(time.Now())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate
+}
+
+func (m *rateMeter) roll(now time.Time) {
+	if !m.mu.TryLock() {
+		return // 其他协程正在滚动窗口
+	}
+	defer m.mu.Unlock()
+
+	elapsed := now.Sub(m.windowStart)
+	if elapsed < rateWindow {
+		return
+	}
+	instant := float64(m.count.Swap(0)) / elapsed.Seconds()
+	if m.rate == 0 {
+		m.rate = instant
+	} else {
+		m.rate = rateAlpha*instant + (1-rateAlpha)*m.rate
+	}
+	// 中间跨过的空窗口按 0 事件衰减
+	if windows := int(elapsed / rateWindow); windows > 1 {
+		m.rate *= math.Pow(1-rateAlpha, float64(windows-1))
+	}
+	m.windowStart = now
+}
+
+// admit 预估排队等待时间，ctx 截止时间早于预估时拒绝
+// position 为调用方入队后的位置（按需求数计），没有吞吐历史时不拒绝
+func (p *Pool[T]) admit(ctx context.Context, op string, position int64) error {
+	if !p.config.AdmissionControl {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	rate := p.dequeueRate.Rate()
+	if rate <= 0 {
+		return nil
+	}
+	estimate := time.Duration(float64(position) / rate * float64(time.Second))
+	if remaining := time.Until(deadline); remaining < estimate {
+		p.rejectedDeadline.Add(1)
+		err := p.newError(op, "", ErrWouldExceedDeadline,
+			fmt.Errorf("estimated wait %v at position %d exceeds remaining %v", estimate, position, remaining))
+		p.emit(EventRejected, "", "deadline", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,520 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Endpoint BalancedPool 的一个后端
+type Endpoint[T any] struct {
+	Name   string
+	Conn   Conn[T]
+	Weight int // WeightedRandom 使用，<=0 按 1 计
+}
+
+// BackendInfo 交给 Balancer 挑选的后端快照，只包含当前健康的后端
+type BackendInfo struct {
+	Name   string
+	Weight int
+	InUse  int64
+	Idle   int64
+}
+
+// Balancer 后端选择策略，返回 backends 中的下标，必须并发安全
+type Balancer interface {
+	Pick(backends []BackendInfo) int
+}
+
+// NewRoundRobinBalancer 轮询
+func NewRoundRobinBalancer() Balancer { return &roundRobin{} }
+
+// NewLeastInUseBalancer 选在用连接最少的后端
+func NewLeastInUseBalancer() Balancer { return leastInUse{} }
+
+// NewWeightedRandomBalancer 按 Weight 加权随机
+func NewWeightedRandomBalancer() Balancer { return weightedRandom{} }
+
+type roundRobin struct{ next atomic.Uint64 }
+
+func (r *roundRobin) Pick(backends []BackendInfo) int {
+	return int((r.next.Add(1) - 1) % uint64(len(backends)))
+}
+
+type leastInUse struct{}
+
+func (leastInUse) Pick(backends []BackendInfo) int {
+	best := 0
+	for i, b := range backends {
+		if b.InUse < backends[best].InUse {
+			best = i
+		}
+	}
+	return best
+}
+
+type weightedRandom struct{}
+
+func (weightedRandom) Pick(backends []BackendInfo) int {
+	total := 0
+	for _, b := range backends {
+		total += max(b.Weight, 1)
+	}
+	n := rand.IntN(total)
+	for i, b := range backends {
+		n -= max(b.Weight, 1)
+		if n < 0 {
+			return i
+		}
+	}
+	return len(backends) - 1
+}
+
+// BalancedPoolConfig BalancedPool 的配置
+type BalancedPoolConfig struct {
+	PoolConfig // 每个后端子池的配置
+
+	Balancer         Balancer      // 选择策略，默认轮询
+	FailureThreshold float64       // 最近 FailureWindow 次 Create/Ping 的失败率达到该值时下线，默认 0.5
+	FailureWindow    int           // 失败率统计窗口，默认 20
+	MinSamples       int           // 窗口内样本不足时不判定，默认 5
+	ProbeInterval    time.Duration // 下线后端的探活间隔，默认 1s
+	DrainTimeout     time.Duration // 移除后端时等待在用连接归还的最长时间，默认 30s
+
+	// 后端上下线回调，在触发状态变化的协程里同步调用，应尽快返回
+	OnEndpointDown func(name string, err error)
+	OnEndpointUp   func(name string)
+}
+
+// backend 一个后端：子池 + 健康窗口
+type backend[T any] struct {
+	id     int // 子池创建的资源以它标记归属（Resource.shard）
+	name   string
+	weight atomic.Int64
+	conn   Conn[T] // 原始连接控制器，探活时直接使用
+	pool   *Pool[T]
+
+	mu       sync.Mutex
+	outcomes []bool // 环形窗口，true 表示失败
+	next     int
+	samples  int
+	failures int
+	lastErr  error
+
+	down      atomic.Bool
+	downCount atomic.Int64
+}
+
+// record 记录一次 Create/Ping 结果，返回是否应当下线
+func (b *backend[T]) record(err error, window, minSamples int, threshold float64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.outcomes) != window {
+		b.outcomes = make([]bool, window)
+		b.next, b.samples, b.failures = 0, 0, 0
+	}
+	if b.samples == window && b.outcomes[b.next] {
+		b.failures--
+	}
+	failed := err != nil
+	b.outcomes[b.next] = failed
+	b.next = (b.next + 1) % window
+	if b.samples < window {
+		b.samples++
+	}
+	if failed {
+		b.failures++
+		b.lastErr = err
+	}
+	return b.samples >= minSamples && float64(b.failures)/float64(b.samples) >= threshold
+}
+
+func (b *backend[T]) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.outcomes)
+	b.next, b.samples, b.failures = 0, 0, 0
+}
+
+func (b *backend[T]) health() (samples, failures int64, lastErr error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int64(b.samples), int64(b.failures), b.lastErr
+}
+
+// observedConn 包装后端的连接控制器，把 Create/Ping 结果计入健康窗口
+type observedConn[T any] struct {
+	Conn[T]
+	bp *BalancedPool[T]
+	b  *backend[T]
+}
+
+func (o observedConn[T]) Create() (T, error) {
+	c, err := o.Conn.Create()
+	o.bp.observe(o.b, err)
+	return c, err
+}
+
+func (o observedConn[T]) Ping(c T) error {
+	err := o.Conn.Ping(c)
+	o.bp.observe(o.b, err)
+	return err
+}
+
+// observedLabeledConn 后端实现了 LabeledConn 时使用，子池据此照常记录标签、按标签新建
+// 只在内层实现时才包这一层，否则 GetMatching 会误以为可以按标签新建
+type observedLabeledConn[T any] struct {
+	observedConn[T]
+	lc LabeledConn[T]
+}
+
+func (o observedLabeledConn[T]) CreateLabeled(selector func(Labels) bool) (T, Labels, error) {
+	c, labels, err := o.lc.CreateLabeled(selector)
+	o.bp.observe(o.b, err)
+	return c, labels, err
+}
+
+// observed 包装后端的连接控制器，把 Create/Ping 结果计入 b 的健康窗口
+func (bp *BalancedPool[T]) observed(b *backend[T], cc Conn[T]) Conn[T] {
+	o := observedConn[T]{Conn: cc, bp: bp, b: b}
+	if lc, ok := cc.(LabeledConn[T]); ok {
+		return observedLabeledConn[T]{observedConn: o, lc: lc}
+	}
+	return o
+}
+
+// BalancedPool 多个后端之上的一个逻辑池，按 Balancer 分配 Get，失败率超阈值的后端下线直到探活成功
+// 后端集合可以通过 Reconcile 动态调整
+type BalancedPool[T any] struct {
+	config   BalancedPoolConfig
+	backends atomic.Pointer[[]*backend[T]] // 当前参与路由的后端
+
+	mu     sync.RWMutex
+	owners map[int]*backend[T] // 所有还有连接未处理完的后端（含排空中的），Put 据此找归属
+	nextID int
+
+	reconcileMu sync.Mutex
+	draining    sync.WaitGroup
+
+	closeCtx context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	closed   atomic.Bool
+	events   *eventBus // 后端熔断状态变化
+}
+
+// NewBalancedPool 为每个 Endpoint 创建一个子池
+func NewBalancedPool[T any](config BalancedPoolConfig, endpoints []Endpoint[T]) *BalancedPool[T] {
+	if config.Balancer == nil {
+		config.Balancer = NewRoundRobinBalancer()
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 0.5
+	}
+	if config.FailureWindow <= 0 {
+		config.FailureWindow = 20
+	}
+	if config.MinSamples <= 0 {
+		config.MinSamples = 5
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = time.Second
+	}
+
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bp := &BalancedPool[T]{
+		config:   config,
+		owners:   make(map[int]*backend[T]),
+		closeCtx: ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		events:   newEventBus(),
+	}
+	bp.backends.Store(&[]*backend[T]{})
+	bp.Reconcile(endpoints)
+	go bp.probeLoop()
+	return bp
+}
+
+// Reconcile 把后端集合调整为 endpoints（按 Name 比对）：
+// 新增的创建子池；仍在的保留子池，只更新权重；移除的立即停止路由，
+// 关闭空闲连接，等在用连接归还（最多 DrainTimeout）后关闭子池。进行中的租约在此期间仍然有效
+func (bp *BalancedPool[T]) Reconcile(endpoints []Endpoint[T]) error {
+	if bp.closed.Load() {
+		return bp.newError("reconcile", ErrPoolClosed, nil)
+	}
+	bp.reconcileMu.Lock()
+	defer bp.reconcileMu.Unlock()
+
+	current := make(map[string]*backend[T])
+	for _, b := range *bp.backends.Load() {
+		current[b.name] = b
+	}
+	next := make([]*backend[T], 0, len(endpoints))
+	for _, ep := range endpoints {
+		b, ok := current[ep.Name]
+		if ok {
+			delete(current, ep.Name)
+		} else {
+			b = bp.newBackend(ep)
+		}
+		b.weight.Store(int64(ep.Weight))
+		next = append(next, b)
+	}
+	bp.backends.Store(&next)
+
+	for _, b := range current {
+		bp.retire(b)
+	}
+	return nil
+}
+
+func (bp *BalancedPool[T]) newBackend(ep Endpoint[T]) *backend[T] {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	b := &backend[T]{id: bp.nextID, name: ep.Name, conn: ep.Conn}
+	bp.nextID++
+	c := bp.config.PoolConfig
+	c.Name = ep.Name
+	if bp.config.Name != "" {
+		c.Name = bp.config.Name + "/" + ep.Name
+	}
+	b.pool = newPool(c, bp.observed(b, ep.Conn), poolOptions[T]{shard: b.id})
+	bp.owners[b.id] = b
+	return b
+}
+
+// retire 排空并关闭已移除的后端
+// 子池先标记为 retired：之后落到它上面的 Get 立即返回 ErrPoolClosed，Actor 也不再补建连接
+func (bp *BalancedPool[T]) retire(b *backend[T]) {
+	b.pool.retired.Store(true)
+	b.pool.closeIdle()
+	// 正在该后端排队的 Get 立即收到 ErrPoolClosed，由 BalancedPool.Get 换其他后端
+	b.pool.waitQueue.Clear()
+	bp.draining.Add(1)
+	go func() {
+		defer bp.draining.Done()
+		timer := time.NewTimer(bp.config.DrainTimeout)
+		defer timer.Stop()
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+	wait:
+		for b.pool.inUse.Load() > 0 {
+			select {
+			case <-ticker.C:
+			case <-timer.C:
+				break wait
+			case <-bp.closeCtx.Done():
+				break wait
+			}
+		}
+		b.pool.Close()
+		bp.forget(b)
+	}()
+}
+
+// forget 已关闭的后端没有未归还的连接时从 owners 中删除；
+// 超时后仍有租约的保留，迟到的 Put 还能找到它并关闭连接
+func (bp *BalancedPool[T]) forget(b *backend[T]) {
+	if !b.pool.IsClosed() || b.pool.inUse.Load() > 0 {
+		return
+	}
+	bp.mu.Lock()
+	delete(bp.owners, b.id)
+	bp.mu.Unlock()
+}
+
+func (bp *BalancedPool[T]) newError(op string, kind, cause error) *PoolError {
+	return &PoolError{Op: op, Pool: bp.config.Name, Kind: kind, Err: cause}
+}
+
+// observe 记录一次结果，失败率越过阈值时下线并回调
+func (bp *BalancedPool[T]) observe(b *backend[T], err error) {
+	if !b.record(err, bp.config.FailureWindow, bp.config.MinSamples, bp.config.FailureThreshold) {
+		return
+	}
+	if b.down.CompareAndSwap(false, true) {
+		b.downCount.Add(1)
+		_, _, lastErr := b.health()
+		bp.events.publish(Event{Kind: EventBreakerStateChanged, Detail: b.name + " open", Err: lastErr})
+		if bp.config.OnEndpointDown != nil {
+			bp.config.OnEndpointDown(b.name, lastErr)
+		}
+	}
+}
+
+// probeLoop 定期对下线的后端探活：Create + Ping 成功即恢复
+func (bp *BalancedPool[T]) probeLoop() {
+	defer close(bp.done)
+	ticker := time.NewTicker(bp.config.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-bp.closeCtx.Done():
+			return
+		case <-ticker.C:
+			for _, b := range *bp.backends.Load() {
+				if b.down.Load() && bp.probe(b) == nil {
+					b.reset()
+					if !b.down.CompareAndSwap(true, false) {
+						continue
+					}
+					bp.events.publish(Event{Kind: EventBreakerStateChanged, Detail: b.name + " closed"})
+					if bp.config.OnEndpointUp != nil {
+						bp.config.OnEndpointUp(b.name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// probe 绕过子池直接建一个连接并 Ping，用完即关
+func (bp *BalancedPool[T]) probe(b *backend[T]) error {
+	c, err := b.conn.Create()
+	if err != nil {
+		return err
+	}
+	defer b.conn.Close(c)
+	return b.conn.Ping(c)
+}
+
+// Get 从健康的后端中按 Balancer 挑一个取连接
+// 选中的后端忙（ErrPoolBusy）、校验失败或已关闭时换下一个，ctx 结束时直接返回
+func (bp *BalancedPool[T]) Get(ctx context.Context) (*Resource[T], error) {
+	if bp.closed.Load() {
+		return nil, bp.newError("get", ErrPoolClosed, nil)
+	}
+	candidates := bp.healthy()
+	var lastErr error
+	for len(candidates) > 0 {
+		infos := make([]BackendInfo, len(candidates))
+		for i, b := range candidates {
+			infos[i] = BackendInfo{
+				Name:   b.name,
+				Weight: int(b.weight.Load()),
+				InUse:  b.pool.inUse.Load(),
+				Idle:   int64(b.pool.idle.Len()),
+			}
+		}
+		i := bp.config.Balancer.Pick(infos)
+		if i < 0 || i >= len(candidates) {
+			i = 0
+		}
+		r, err := candidates[i].pool.Get(ctx)
+		if err == nil {
+			return r, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+		candidates = append(candidates[:i:i], candidates[i+1:]...)
+	}
+	return nil, bp.newError("get", ErrNoHealthyBackend, lastErr)
+}
+
+func (bp *BalancedPool[T]) healthy() []*backend[T] {
+	backends := *bp.backends.Load()
+	out := make([]*backend[T], 0, len(backends))
+	for _, b := range backends {
+		if !b.down.Load() {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Put 归还到连接所属的后端；后端已被移除时连接随之关闭，不视为错误
+func (bp *BalancedPool[T]) Put(res *Resource[T]) error {
+	if res == nil {
+		return nil
+	}
+	bp.mu.RLock()
+	b, ok := bp.owners[res.shard]
+	bp.mu.RUnlock()
+	if !ok {
+		return bp.newError("put", ErrPoolClosed, nil)
+	}
+	err := b.pool.Put(res)
+	if b.pool.retired.Load() && errors.Is(err, ErrPoolClosed) {
+		bp.forget(b)
+		return nil
+	}
+	return err
+}
+
+// BackendStats 每个后端子池的统计，另附 healthy、health_samples、health_failures、down_count
+func (bp *BalancedPool[T]) BackendStats(ctx context.Context) (map[string]map[string]int64, error) {
+	if bp.closed.Load() {
+		return nil, bp.newError("stats", ErrPoolClosed, nil)
+	}
+	backends := *bp.backends.Load()
+	out := make(map[string]map[string]int64, len(backends))
+	for _, b := range backends {
+		stats, err := b.pool.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		samples, failures, _ := b.health()
+		stats["healthy"] = 1
+		if b.down.Load() {
+			stats["healthy"] = 0
+		}
+		stats["health_samples"] = samples
+		stats["health_failures"] = failures
+		stats["down_count"] = b.downCount.Load()
+		out[b.name] = stats
+	}
+	return out, nil
+}
+
+// Stats 所有后端统计求和，另附 backends 和 healthy_backends
+func (bp *BalancedPool[T]) Stats(ctx context.Context) (map[string]int64, error) {
+	per, err := bp.BackendStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	total := make(map[string]int64)
+	for _, stats := range per {
+		for k, v := range stats {
+			total[k] += v
+		}
+	}
+	total["backends"] = int64(len(per))
+	total["healthy_backends"] = total["healthy"]
+	delete(total, "healthy")
+	return total, nil
+}
+
+// Close 关闭所有后端子池，重复调用是安全的
+func (bp *BalancedPool[T]) Close() {
+	if !bp.closed.CompareAndSwap(false, true) {
+		return
+	}
+	bp.cancel()
+	<-bp.done
+	bp.draining.Wait()
+	for _, b := range *bp.backends.Load() {
+		b.pool.Close()
+	}
+	bp.events.close()
+}
+
+// Subscribe 订阅后端熔断状态变化（EventBreakerStateChanged，Detail 为 "<后端名> open|closed"）
+// 语义同 Pool.Subscribe；各后端子池自身的连接事件不在这里发布
+func (bp *BalancedPool[T]) Subscribe(buffer int) (<-chan Event, func()) {
+	return bp.events.subscribe(buffer)
+}
+
+// IsClosed 返回 BalancedPool 是否已关闭
+func (bp *BalancedPool[T]) IsClosed() bool {
+	return bp.closed.Load()
+}
@@ -0,0 +1,191 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RedHuang-0622/TemplatePoolByGO/util/request_queue"
+)
+
+// GetN 一次性获取 n 个资源（全有或全无）
+// 要么 n 个资源一起返回，要么返回错误且不持有任何资源
+// 等待期间以单个批量等待者排队，扩缩容按 n 个需求计算；
+// 已拿到的部分不会在排队时占着不放，避免多个批量任务各持一部分互相死锁
+func (p *Pool[T]) GetN(ctx context.Context, n int) (res []*resource[T], err error) {
+	start := time.Now()
+	defer func() {
+		// n==1 走 Get，已经计过
+		if err == nil && n > 1 {
+			d := time.Since(start)
+			for range res {
+				p.waitLatency.observe(d)
+			}
+		}
+	}()
+	if p.closed.Load() {
+		return nil, p.newError("getn", "", ErrPoolClosed, nil)
+	}
+	if err := p.waitResume(ctx, "getn"); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+	if maxSize := p.maxSize.Load(); int64(n) > maxSize {
+		return nil, p.newError("getn", "", ErrBatchTooLarge,
+			fmt.Errorf("requested %d, MaxSize %d", n, maxSize))
+	}
+	if n == 1 {
+		r, err := p.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return []*resource[T]{r}, nil
+	}
+
+	// 快速路径：没人排队且空闲连接足够时直接取走 n 个
+	got := make([]*resource[T], 0, n)
+	if p.waitQueue.Len() == 0 {
+		for len(got) < n {
+			r, ok := p.idle.Pop()
+			if !ok {
+				break
+			}
+			got = append(got, r)
+		}
+		if len(got) == n {
+			return p.validateBatch(got)
+		}
+	}
+
+	if int64(p.waitQueue.Len()) >= p.config.MaxWaitQueue {
+		for _, r := range got {
+			p.handBack(r)
+		}
+		p.emit(EventRejected, "", "busy", ErrPoolBusy)
+		return nil, ErrPoolBusy
+	}
+	if err := p.admit(ctx, "getn", p.waitQueue.Demand()+int64(n)-int64(len(got))); err != nil {
+		for _, r := range got {
+			p.handBack(r)
+		}
+		return nil, err
+	}
+	waiter := p.enqueue(ctx, n)
+	if p.closed.Load() {
+		p.abortBatch(waiter, nil)
+		for _, r := range got {
+			p.handBack(r)
+		}
+		return nil, p.newError("getn", "", ErrPoolClosed, nil)
+	}
+
+	// 已取的部分和空闲集合里的连接都按 FIFO 重新交给队列（可能正好交给自己）
+	// 入队前后可能恰好被 Pause：暂停期间不把空闲连接交给等待者
+	for _, r := range got {
+		p.handBack(r)
+	}
+	if !p.gate.paused() {
+		p.pumpIdle()
+	}
+	p.notifyExpand()
+
+	span := p.trace.start(ctx, SpanGetWait, func() []Attr {
+		return []Attr{{Key: AttrDemand, Value: n}, {Key: AttrWaiting, Value: p.waitQueue.Len()}}
+	})
+	received := make([]*resource[T], 0, n)
+	for len(received) < n {
+		select {
+		case r := <-waiter.Ch:
+			p.dequeueRate.Mark()
+			received = append(received, r)
+		case <-ctx.Done():
+			span.End(ctx.Err())
+			p.abortBatch(waiter, received)
+			p.getTimeouts.Add(1)
+			p.emit(EventWaiterTimedOut, "", "", ctx.Err())
+			return nil, ctx.Err()
+		case <-p.closeCtx.Done():
+			err := p.newError("getn", "", ErrPoolClosed, nil)
+			span.End(err)
+			p.abortBatch(waiter, received)
+			return nil, err
+		}
+	}
+	span.End(nil)
+	return p.validateBatch(received)
+}
+
+// PutN 批量归还，返回所有失败的合并错误
+func (p *Pool[T]) PutN(res []*resource[T]) error {
+	var errs []error
+	for _, r := range res {
+		if err := p.Put(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pumpIdle 把空闲集合中的连接交给等待者，直到队列或空闲集合为空
+func (p *Pool[T]) pumpIdle() {
+	for p.waitQueue.Len() > 0 {
+		r, ok := p.idle.Pop()
+		if !ok {
+			return
+		}
+		if !p.waitQueue.TryDequeue(r) {
+			p.handBack(r)
+			return
+		}
+	}
+}
+
+// removeIdle 从空闲集合摘除 fn 匹配的连接，然后把留下的空闲连接补交给等待者：
+// chanIdle 的 RemoveIf 会把 channel 整个倒出来再放回，期间到来的 Get 看到空集合就排了队，
+// 放回的连接不会主动交给它，池已满时它只能等到超时
+func (p *Pool[T]) removeIdle(fn func(r *resource[T]) bool) []*resource[T] {
+	removed := p.idle.RemoveIf(fn)
+	if !p.gate.paused() {
+		p.pumpIdle()
+	}
+	return removed
+}
+
+// abortBatch 取消批量等待者：收齐已被认领的资源后全部交还
+func (p *Pool[T]) abortBatch(waiter *request_queue.LockFreeWaiter[*resource[T]], received []*resource[T]) {
+	claimed := p.waitQueue.RemoveBatch(waiter)
+	for len(received) < claimed {
+		received = append(received, <-waiter.Ch)
+	}
+	for _, r := range received {
+		if p.closed.Load() {
+			p.destroy(r, ReasonPoolClosed)
+			continue
+		}
+		p.handBack(r)
+	}
+}
+
+// validateBatch 逐个校验，任一失败则把已校验通过的全部交还，保证全有或全无
+func (p *Pool[T]) validateBatch(batch []*resource[T]) ([]*resource[T], error) {
+	out := make([]*resource[T], 0, len(batch))
+	for i, r := range batch {
+		v, err := p.validateAndReturn(r)
+		if err != nil {
+			for _, ok := range out {
+				p.inUse.Add(-1)
+				p.checkin(ok)
+				p.handBack(ok)
+			}
+			for _, rest := range batch[i+1:] {
+				p.handBack(rest)
+			}
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
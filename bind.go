@@ -0,0 +1,106 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// bindKey 区分不同池绑定在同一个 context 上的资源
+type bindKey struct{ pool any }
+
+// binding 绑定在 context 上的资源租约
+// refs = 未释放的 Bind 次数 + 通过绑定 context 借出、尚未 Put 的次数，归零时才真正归还
+type binding[T any] struct {
+	mu   sync.Mutex
+	res  *resource[T] // 第一次 Get 时才取
+	refs int
+}
+
+// Bind 把一个资源钉在返回的 context 上：之后用它（或其子 context）Get 都拿到同一个资源，不经过等待队列，
+// 对应的 Put 只减引用；最后一次 release / Put 才真正归还。在已绑定的 context 上再次 Bind 是可重入的
+// 资源在第一次 Get 时才取，Bind 本身不会阻塞；同一绑定上的资源不能被多个 goroutine 并发使用
+// 只有 Get 认绑定，GetN、GetAffinity、GetMatching、Acquire 忽略它，照常从池里取新的资源
+func (p *Pool[T]) Bind(ctx context.Context) (context.Context, func()) {
+	if p.closed.Load() {
+		return ctx, func() {}
+	}
+	if b, ok := ctx.Value(bindKey{p}).(*binding[T]); ok {
+		b.mu.Lock()
+		live := b.refs > 0
+		if live {
+			b.refs++
+		}
+		b.mu.Unlock()
+		if live {
+			return ctx, p.releaseOnce(b)
+		}
+	}
+	b := &binding[T]{refs: 1}
+	return context.WithValue(ctx, bindKey{p}, b), p.releaseOnce(b)
+}
+
+func (p *Pool[T]) releaseOnce(b *binding[T]) func() {
+	var once sync.Once
+	return func() { once.Do(func() { p.unbind(b) }) }
+}
+
+// getBound 返回绑定的资源，第一次调用时从池里取；绑定已全部释放时 ok=false，由调用方走普通 Get
+// 取资源时不持有 b.mu，排队期间 release 不会被阻塞
+func (p *Pool[T]) getBound(ctx context.Context, b *binding[T]) (res *resource[T], ok bool, err error) {
+	b.mu.Lock()
+	if b.refs == 0 {
+		b.mu.Unlock()
+		return nil, false, nil
+	}
+	if b.res != nil {
+		b.refs++
+		res = b.res
+		b.mu.Unlock()
+		return res, true, nil
+	}
+	b.mu.Unlock()
+
+	r, err := p.get(ctx, nil)
+	if err != nil {
+		return nil, true, err
+	}
+	b.mu.Lock()
+	switch {
+	case b.refs == 0:
+		// 排队期间绑定已全部释放：当作普通 Get 的结果交给调用方
+		b.mu.Unlock()
+		return r, true, nil
+	case b.res != nil:
+		// 同一绑定上另一个 Get 先取到了：用它的，多取的这个归还
+		b.refs++
+		res = b.res
+		b.mu.Unlock()
+		p.put(r)
+		return res, true, nil
+	}
+	r.bound = b
+	b.res = r
+	b.refs++
+	b.mu.Unlock()
+	return r, true, nil
+}
+
+// unbind 减一次引用，归零时真正归还资源；已经归零（Put 多于 Get）时什么都不做
+func (p *Pool[T]) unbind(b *binding[T]) error {
+	b.mu.Lock()
+	if b.refs == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	b.refs--
+	var res *resource[T]
+	if b.refs == 0 {
+		res, b.res = b.res, nil
+	}
+	b.mu.Unlock()
+	if res == nil {
+		return nil
+	}
+	res.bound = nil
+	return p.put(res)
+}
@@ -1,28 +1,60 @@
 package pool
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+)
 
 // Resource 资源包装器（导出供测试使用）
+// 运行期统计都是原子字段，Snapshot 读取时不加锁、不阻塞 Get/Put
 type Resource[T any] struct {
 	ID         string
-	createTime time.Time
-	updateTime time.Time
+	createTime atomic.Int64 // UnixNano，重连后更新
+	updateTime atomic.Int64 // 最近一次借出的时间（UnixNano）
 	Conn       T
-	retryCount int // 重连次数
+	retryCount atomic.Int64 // 重连次数
+	useCount   atomic.Int64 // 借出次数
+	state      atomic.Int32 // ResourceState
+	lastErr    atomic.Pointer[error]
+	holder     atomic.Pointer[string] // LeakTracking 开启时记录借出方的调用位置
+	shard      int                    // ShardedPool 中所属子池下标
+	owner      *Pool[T]               // 创建它的池，KeyedPool 据此把归还路由回原来的子池
+
+	affinityKey string // 最近一次通过 GetAffinity 服务的 key
+	labels      Labels // 资源标签，GetMatching 据此挑选
+
+	bound *binding[T] // 通过 Bind 借出时指向所属绑定
+	gen   int64       // 创建时池的代数，早于当前代的在归还时关闭
 }
 
+// AffinityKey 返回最近一次通过 GetAffinity 取用该连接时的 key，从未使用过时为空
+func (r *Resource[T]) AffinityKey() string { return r.affinityKey }
+
 // 内部使用 resource 作为别名
 type resource[T any] = Resource[T]
 
 type PoolConfig struct {
+	Name             string // 池名称，出现在 PoolError 中便于定位
 	MinSize          int64
 	MaxSize          int64
 	SurviveTime      time.Duration
 	MonitorInterval  time.Duration
-	IdleBufferFactor float64 // channel 缓冲系数
+	IdleBufferFactor float64   // channel 缓冲系数
+	IdleOrder        IdleOrder // 空闲连接取用顺序，默认 FIFO
+	IdleShards       int       // 空闲集合分片数，>1 时使用分片互斥锁实现，降低高并发下的竞争
+	Affinity         bool      // 空闲集合按亲和 key 建索引，GetAffinity 才能命中；开启后忽略 IdleShards
 
 	// 等待队列配置
-	MaxWaitQueue int64 // 新增，建议默认值 10000
+	MaxWaitQueue  int64         // 新增，建议默认值 10000
+	WaitQueueMode WaitQueueMode // 等待队列类型，默认 FIFO
+	PriorityAging time.Duration // 优先级队列老化步长：每等待这么久等效优先级 +1，<=0 关闭
+
+	// 准入控制：按最近的出队吞吐和排队位置预估等待时间，
+	// ctx 截止时间早于预估时直接返回 ErrWouldExceedDeadline，而不是排队后超时
+	AdmissionControl bool
+
+	// Pause 期间新的获取请求排队等待 Resume（默认）还是立即返回 ErrPoolPaused
+	PauseMode PauseMode
 
 	// 重连配置
 	MaxRetries     int           // 最大重试次数
@@ -32,8 +64,22 @@ type PoolConfig struct {
 	// 心跳配置
 	PingInterval time.Duration   // 定期 Ping 连接的间隔
 	OnUnhealthy  func(err error) // 回调钩子
+
+	// 泄漏排查：借出时记录调用方位置，Snapshot 中可见；有一次栈回溯的开销，默认关闭
+	LeakTracking bool
+
+	// 链路追踪：排队等待、Create、Ping、Reset 各产生一个 span，默认 NoopTracer
+	Tracer Tracer
 }
 
+// WaitQueueMode 等待队列类型
+type WaitQueueMode int
+
+const (
+	WaitQueueFIFO     WaitQueueMode = iota // 无锁 FIFO 队列（默认）
+	WaitQueuePriority                      // 按 WithPriority 指定的优先级服务，带老化
+)
+
 func DefaultPoolConfig() PoolConfig {
 	return PoolConfig{
 		MinSize:          5,
@@ -41,12 +87,15 @@ func DefaultPoolConfig() PoolConfig {
 		SurviveTime:      30 * time.Minute,
 		MonitorInterval:  10 * time.Second,
 		IdleBufferFactor: 1.0,
+		IdleOrder:        IdleFIFO,
 		MaxRetries:       3,
 		RetryInterval:    1 * time.Second,
 		ReconnectOnGet:   false, // Get 时 Ping 失败是否自动重连（默认关闭，避免热路径开销）
 		PingInterval:     30 * time.Second,
 		OnUnhealthy:      nil,
 		MaxWaitQueue:     10000,
+		WaitQueueMode:    WaitQueueFIFO,
+		PriorityAging:    time.Second,
 	}
 }
 
@@ -0,0 +1,133 @@
+// Package debughttp 以 JSON 或 HTML 展示已注册连接池的运行状态，用法类似 net/http/pprof：
+//
+//	debughttp.Register("orders", p)
+//	http.Handle("/debug/pool/", debughttp.Handler(debughttp.Options{}))
+//
+// 页面包括 Stats、延迟分布、每个资源的状态、最近一分钟的等待队列深度和最近的生命周期事件；
+// 开启 AllowActions 后还可以 POST 触发 drain / recycle / resize
+package debughttp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	pool "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// Pool debughttp 用到的池方法，*pool.Pool[T] 对任意 T 都满足
+type Pool interface {
+	Stats(ctx context.Context) (map[string]int64, error)
+	Snapshot() ([]pool.ResourceInfo, error)
+	Latency() (pool.LatencyStats, error)
+	RecentEvents() ([]pool.Event, error)
+	Drain(ctx context.Context) error
+	RecycleAll(ctx context.Context, opts pool.RecycleOptions) (pool.RecycleProgress, error)
+	Resize(min, max int64) error
+}
+
+const (
+	depthSamples  = 60          // 等待队列深度保留的采样点数
+	depthInterval = time.Second // 采样间隔
+)
+
+// depthSample 一次等待队列深度采样
+type depthSample struct {
+	Time    time.Time `json:"time"`
+	Waiting int64     `json:"waiting"`
+}
+
+// entry 一个已注册的池及其等待队列深度采样
+type entry struct {
+	pool Pool
+	stop chan struct{}
+
+	mu    sync.Mutex
+	depth [depthSamples]depthSample
+	next  int
+	full  bool
+}
+
+var registry = struct {
+	sync.RWMutex
+	pools map[string]*entry
+}{pools: make(map[string]*entry)}
+
+// Register 以 name 注册一个池并开始每秒采样等待队列深度；同名的旧注册被替换
+func Register(name string, p Pool) {
+	e := &entry{pool: p, stop: make(chan struct{})}
+	registry.Lock()
+	old := registry.pools[name]
+	registry.pools[name] = e
+	registry.Unlock()
+	if old != nil {
+		close(old.stop)
+	}
+	go e.sample()
+}
+
+// Unregister 注销 name 并停止采样，未注册时什么也不做
+func Unregister(name string) {
+	registry.Lock()
+	e := registry.pools[name]
+	delete(registry.pools, name)
+	registry.Unlock()
+	if e != nil {
+		close(e.stop)
+	}
+}
+
+func lookup(name string) (*entry, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	e, ok := registry.pools[name]
+	return e, ok
+}
+
+// names 按字母序返回已注册的池名
+func names() []string {
+	registry.RLock()
+	out := make([]string, 0, len(registry.pools))
+	for name := range registry.pools {
+		out = append(out, name)
+	}
+	registry.RUnlock()
+	sort.Strings(out)
+	return out
+}
+
+func (e *entry) sample() {
+	ticker := time.NewTicker(depthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case now := <-ticker.C:
+			stats, err := e.pool.Stats(context.Background())
+			if err != nil {
+				continue // 池已关闭，保留之前的采样
+			}
+			e.mu.Lock()
+			e.depth[e.next] = depthSample{Time: now, Waiting: stats["waiting_count"]}
+			e.next = (e.next + 1) % depthSamples
+			if e.next == 0 {
+				e.full = true
+			}
+			e.mu.Unlock()
+		}
+	}
+}
+
+// queueDepth 按时间先后返回采样
+func (e *entry) queueDepth() []depthSample {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.full {
+		return append([]depthSample(nil), e.depth[:e.next]...)
+	}
+	out := make([]depthSample, 0, depthSamples)
+	out = append(out, e.depth[e.next:]...)
+	return append(out, e.depth[:e.next]...)
+}
@@ -0,0 +1,169 @@
+package debughttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pool "github.com/RedHuang-0622/TemplatePoolByGO"
+	"github.com/RedHuang-0622/TemplatePoolByGO/debughttp"
+)
+
+type intConn struct{ next atomic.Int64 }
+
+func (c *intConn) Create() (int64, error) { return c.next.Add(1), nil }
+func (c *intConn) Reset(int64) error      { return nil }
+func (c *intConn) Close(int64) error      { return nil }
+func (c *intConn) Ping(int64) error       { return nil }
+
+func newPool(t *testing.T, name string) *pool.Pool[int64] {
+	t.Helper()
+	p := pool.NewPool(pool.PoolConfig{
+		Name:             name,
+		MinSize:          2,
+		MaxSize:          4,
+		IdleBufferFactor: 1.0,
+		MaxWaitQueue:     10,
+	}, &intConn{})
+	time.Sleep(100 * time.Millisecond)
+	debughttp.Register(name, p)
+	t.Cleanup(func() {
+		debughttp.Unregister(name)
+		p.Close()
+	})
+	return p
+}
+
+func getJSON(t *testing.T, u string, v any) int {
+	t.Helper()
+	resp, err := http.Get(u + "?format=json")
+	if err != nil {
+		t.Fatalf("GET %s: %v", u, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && resp.StatusCode == http.StatusOK {
+		t.Fatalf("decode %s: %v", u, err)
+	}
+	return resp.StatusCode
+}
+
+func TestHandler_Views(t *testing.T) {
+	newPool(t, "orders")
+	srv := httptest.NewServer(debughttp.Handler(debughttp.Options{}))
+	defer srv.Close()
+
+	var index struct {
+		Pools []struct {
+			Name  string           `json:"name"`
+			Stats map[string]int64 `json:"stats"`
+		} `json:"pools"`
+	}
+	if code := getJSON(t, srv.URL+"/debug/pool/", &index); code != http.StatusOK {
+		t.Fatalf("index status %d", code)
+	}
+	if len(index.Pools) != 1 || index.Pools[0].Name != "orders" || index.Pools[0].Stats["total_size"] != 2 {
+		t.Fatalf("index = %+v", index)
+	}
+
+	var detail struct {
+		Resources []struct {
+			State string `json:"state"`
+		} `json:"resources"`
+		Events []struct {
+			Kind string `json:"kind"`
+		} `json:"events"`
+		Latency struct {
+			Wait struct {
+				Buckets []any `json:"buckets"`
+			} `json:"wait"`
+		} `json:"latency"`
+	}
+	if code := getJSON(t, srv.URL+"/debug/pool/orders", &detail); code != http.StatusOK {
+		t.Fatalf("detail status %d", code)
+	}
+	if len(detail.Resources) != 2 || detail.Resources[0].State != "idle" {
+		t.Errorf("resources = %+v", detail.Resources)
+	}
+	if len(detail.Events) != 2 || detail.Events[0].Kind != "created" {
+		t.Errorf("events = %+v", detail.Events)
+	}
+	if len(detail.Latency.Wait.Buckets) == 0 {
+		t.Error("latency buckets missing")
+	}
+
+	resp, err := http.Get(srv.URL + "/debug/pool/orders")
+	if err != nil {
+		t.Fatalf("GET html: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("content type %q", ct)
+	}
+	if !strings.Contains(string(body), "resources (2)") || !strings.Contains(string(body), "recent events") {
+		t.Errorf("html page incomplete:\n%s", body)
+	}
+
+	if code := getJSON(t, srv.URL+"/debug/pool/missing", &detail); code != http.StatusNotFound {
+		t.Errorf("unknown pool status %d, want 404", code)
+	}
+}
+
+func postForm(t *testing.T, u string, form url.Values, header http.Header) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, u, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST %s: %v", u, err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestHandler_Actions(t *testing.T) {
+	p := newPool(t, "users")
+	readOnly := httptest.NewServer(debughttp.Handler(debughttp.Options{}))
+	defer readOnly.Close()
+	srv := httptest.NewServer(debughttp.Handler(debughttp.Options{AllowActions: true}))
+	defer srv.Close()
+	resize := url.Values{"min": {"1"}, "max": {"3"}}
+
+	if resp := postForm(t, readOnly.URL+"/debug/pool/users/resize", resize, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("read-only handler: status %d, want 403", resp.StatusCode)
+	}
+	crossSite := http.Header{"Origin": {"http://evil.example"}}
+	if resp := postForm(t, srv.URL+"/debug/pool/users/resize", resize, crossSite); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin POST: status %d, want 403", resp.StatusCode)
+	}
+	if resp := postForm(t, srv.URL+"/debug/pool/users/resize", url.Values{"min": {"5"}, "max": {"1"}}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid resize: status %d, want 400", resp.StatusCode)
+	}
+
+	if resp := postForm(t, srv.URL+"/debug/pool/users/resize", resize, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("resize: status %d", resp.StatusCode)
+	}
+	if stats, _ := p.Stats(context.Background()); stats["max_size"] != 3 {
+		t.Errorf("max_size = %d after resize, want 3", stats["max_size"])
+	}
+	if resp := postForm(t, srv.URL+"/debug/pool/users/recycle", url.Values{"rate": {"2"}, "interval": {"10ms"}}, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("recycle: status %d", resp.StatusCode)
+	}
+	if resp := postForm(t, srv.URL+"/debug/pool/users/drain", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("drain: status %d", resp.StatusCode)
+	}
+	if resp := postForm(t, srv.URL+"/debug/pool/users/explode", nil, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown action: status %d, want 404", resp.StatusCode)
+	}
+}
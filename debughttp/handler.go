@@ -0,0 +1,353 @@
+package debughttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	pool "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// Options Handler 的配置
+type Options struct {
+	Prefix        string                     // 挂载路径，默认 /debug/pool
+	AllowActions  bool                       // 是否允许 POST drain / recycle / resize，默认只读
+	Authorize     func(r *http.Request) bool // 动作请求的鉴权，nil 时只接受来自回环地址的请求
+	ActionTimeout time.Duration              // drain / recycle 的最长执行时间，默认 30s
+}
+
+// Handler 返回挂在 Prefix 下的处理器：
+//
+//	GET  {Prefix}/                 已注册的池列表
+//	GET  {Prefix}/{name}           单个池的详情
+//	POST {Prefix}/{name}/drain     Drain
+//	POST {Prefix}/{name}/recycle   RecycleAll，参数 rate、interval
+//	POST {Prefix}/{name}/resize    Resize，参数 min、max
+//
+// 请求带 ?format=json 或 Accept: application/json 时返回 JSON，否则返回 HTML
+func Handler(opts Options) http.Handler {
+	opts.Prefix = strings.TrimSuffix(opts.Prefix, "/")
+	if opts.Prefix == "" {
+		opts.Prefix = "/debug/pool"
+	}
+	if opts.ActionTimeout <= 0 {
+		opts.ActionTimeout = 30 * time.Second
+	}
+	h := &handler{opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+opts.Prefix, h.index)
+	mux.HandleFunc("GET "+opts.Prefix+"/{$}", h.index)
+	mux.HandleFunc("GET "+opts.Prefix+"/{name}", h.detail)
+	mux.HandleFunc("POST "+opts.Prefix+"/{name}/{action}", h.action)
+	return mux
+}
+
+type handler struct {
+	opts Options
+}
+
+type poolSummary struct {
+	Name  string           `json:"name"`
+	Stats map[string]int64 `json:"stats,omitempty"`
+	Error string           `json:"error,omitempty"`
+}
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	var pools []poolSummary
+	for _, name := range names() {
+		e, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		s := poolSummary{Name: name}
+		stats, err := e.pool.Stats(r.Context())
+		s.Stats, s.Error = stats, errString(err)
+		pools = append(pools, s)
+	}
+	if wantJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]any{"pools": pools})
+		return
+	}
+	render(w, "index", map[string]any{"Prefix": h.opts.Prefix, "Pools": pools})
+}
+
+type report struct {
+	Name       string           `json:"name"`
+	Error      string           `json:"error,omitempty"`
+	Stats      map[string]int64 `json:"stats,omitempty"`
+	Latency    latencyView      `json:"latency"`
+	Resources  []resourceView   `json:"resources"`
+	QueueDepth []depthSample    `json:"queue_depth"`
+	Events     []eventView      `json:"events"`
+}
+
+type latencyView struct {
+	Wait histogramView `json:"wait"`
+	Hold histogramView `json:"hold"`
+}
+
+type histogramView struct {
+	Count   int64        `json:"count"`
+	Mean    string       `json:"mean"`
+	P50     string       `json:"p50"`
+	P99     string       `json:"p99"`
+	Buckets []bucketView `json:"buckets"`
+}
+
+type bucketView struct {
+	Le    string `json:"le"` // 桶上界，最后一个桶为 +Inf
+	Count int64  `json:"count"`
+}
+
+type resourceView struct {
+	ID             string    `json:"id"`
+	State          string    `json:"state"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastUsedAt     time.Time `json:"last_used_at"`
+	UseCount       int64     `json:"use_count"`
+	ReconnectCount int64     `json:"reconnect_count"`
+	LastError      string    `json:"last_error,omitempty"`
+	Holder         string    `json:"holder,omitempty"`
+}
+
+type eventView struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"kind"`
+	Resource string    `json:"resource,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func (h *handler) detail(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	e, ok := lookup(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	rep := buildReport(r.Context(), name, e)
+	if wantJSON(r) {
+		writeJSON(w, http.StatusOK, rep)
+		return
+	}
+	render(w, "detail", map[string]any{
+		"Prefix":       h.opts.Prefix,
+		"Report":       rep,
+		"AllowActions": h.opts.AllowActions,
+	})
+}
+
+// buildReport 汇总一个池的全部信息；池已关闭时只带错误和历史采样
+func buildReport(ctx context.Context, name string, e *entry) report {
+	rep := report{Name: name, QueueDepth: e.queueDepth()}
+	stats, err := e.pool.Stats(ctx)
+	if err != nil {
+		rep.Error = err.Error()
+		return rep
+	}
+	rep.Stats = stats
+	if lat, err := e.pool.Latency(); err == nil {
+		rep.Latency = latencyView{Wait: histogram(lat.Wait), Hold: histogram(lat.Hold)}
+	}
+	if snap, err := e.pool.Snapshot(); err == nil {
+		for _, info := range snap {
+			rep.Resources = append(rep.Resources, resourceView{
+				ID:             info.ID,
+				State:          info.State.String(),
+				CreatedAt:      info.CreatedAt,
+				LastUsedAt:     info.LastUsedAt,
+				UseCount:       info.UseCount,
+				ReconnectCount: info.ReconnectCount,
+				LastError:      errString(info.LastError),
+				Holder:         info.Holder,
+			})
+		}
+	}
+	if events, err := e.pool.RecentEvents(); err == nil {
+		// 最新的在前
+		for i := len(events) - 1; i >= 0; i-- {
+			ev := events[i]
+			rep.Events = append(rep.Events, eventView{
+				Time:     ev.Time,
+				Kind:     ev.Kind.String(),
+				Resource: ev.Resource,
+				Detail:   ev.Detail,
+				Error:    errString(ev.Err),
+			})
+		}
+	}
+	return rep
+}
+
+func histogram(h pool.LatencyHistogram) histogramView {
+	v := histogramView{
+		Count: h.Count,
+		Mean:  h.Mean().String(),
+		P50:   h.Quantile(0.5).String(),
+		P99:   h.Quantile(0.99).String(),
+	}
+	for i, c := range h.Counts {
+		le := "+Inf"
+		if i < len(h.Bounds) {
+			le = h.Bounds[i].String()
+		}
+		v.Buckets = append(v.Buckets, bucketView{Le: le, Count: c})
+	}
+	return v
+}
+
+func (h *handler) action(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(r) {
+		http.Error(w, "pool actions are disabled or not authorized", http.StatusForbidden)
+		return
+	}
+	name := r.PathValue("name")
+	e, ok := lookup(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.opts.ActionTimeout)
+	defer cancel()
+
+	result := map[string]any{"pool": name, "action": r.PathValue("action")}
+	var err error
+	switch r.PathValue("action") {
+	case "drain":
+		err = e.pool.Drain(ctx)
+	case "recycle":
+		var opts pool.RecycleOptions
+		if opts.Rate, err = intParam(r, "rate", 0); err != nil {
+			break
+		}
+		if opts.Interval, err = durationParam(r, "interval"); err != nil {
+			break
+		}
+		var progress pool.RecycleProgress
+		progress, err = e.pool.RecycleAll(ctx, opts)
+		result["progress"] = progress
+	case "resize":
+		var min, max int
+		if min, err = intParam(r, "min", -1); err != nil {
+			break
+		}
+		if max, err = intParam(r, "max", -1); err != nil {
+			break
+		}
+		err = e.pool.Resize(int64(min), int64(max))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		status = errorStatus(err)
+		result["error"] = err.Error()
+	}
+	if wantJSON(r) {
+		writeJSON(w, status, result)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	// 页面表单提交：回到详情页
+	http.Redirect(w, r, h.opts.Prefix+"/"+url.PathEscape(name), http.StatusSeeOther)
+}
+
+// allowed 动作请求需要显式开启；拒绝浏览器的跨站提交，再交给 Authorize 或回环地址检查
+func (h *handler) allowed(r *http.Request) bool {
+	if !h.opts.AllowActions {
+		return false
+	}
+	if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return false
+		}
+	}
+	if h.opts.Authorize != nil {
+		return h.opts.Authorize(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// errorStatus 把池返回的错误映射为 HTTP 状态码
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errBadParam), errors.Is(err, pool.ErrInvalidSize):
+		return http.StatusBadRequest
+	case errors.Is(err, pool.ErrDrainInProgress), errors.Is(err, pool.ErrRecycleInProgress),
+		errors.Is(err, pool.ErrPoolClosed):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+var errBadParam = errors.New("bad parameter")
+
+// intParam 读取整数参数，缺省时 def < 0 表示必填
+func intParam(r *http.Request, key string, def int) (int, error) {
+	v := r.FormValue(key)
+	if v == "" {
+		if def < 0 {
+			return 0, errors.Join(errBadParam, errors.New(key+" is required"))
+		}
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.Join(errBadParam, err)
+	}
+	return n, nil
+}
+
+func durationParam(r *http.Request, key string) (time.Duration, error) {
+	v := r.FormValue(key)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, errors.Join(errBadParam, err)
+	}
+	return d, nil
+}
+
+func wantJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,125 @@
+package debughttp
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline 把等待队列深度采样画成一行字符柱状图
+func sparkline(samples []depthSample) string {
+	var peak int64
+	for _, s := range samples {
+		peak = max(peak, s.Waiting)
+	}
+	var b strings.Builder
+	for _, s := range samples {
+		i := 0
+		if peak > 0 {
+			i = int(s.Waiting * int64(len(sparkBars)-1) / peak)
+		}
+		b.WriteRune(sparkBars[i])
+	}
+	return b.String()
+}
+
+func peakDepth(samples []depthSample) int64 {
+	var peak int64
+	for _, s := range samples {
+		peak = max(peak, s.Waiting)
+	}
+	return peak
+}
+
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"sparkline": sparkline,
+	"peak":      peakDepth,
+}).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.}}</title>
+<style>
+body{font-family:monospace;margin:1em 2em}
+table{border-collapse:collapse;margin-bottom:1em}
+td,th{border:1px solid #ccc;padding:2px 8px;text-align:left}
+th{background:#eee}
+.spark{font-size:1.4em;letter-spacing:-1px}
+form{display:inline-block;margin-right:1em}
+</style></head><body>
+{{end}}
+
+{{define "index"}}{{template "head" "pools"}}
+<h1>pools</h1>
+{{if not .Pools}}<p>no pools registered</p>{{end}}
+<table>
+<tr><th>name</th><th>total</th><th>idle</th><th>in use</th><th>waiting</th><th>error</th></tr>
+{{range .Pools}}<tr>
+<td><a href="{{$.Prefix}}/{{.Name}}">{{.Name}}</a></td>
+<td>{{index .Stats "total_size"}}</td><td>{{index .Stats "pool_available"}}</td>
+<td>{{index .Stats "pool_in_use"}}</td><td>{{index .Stats "waiting_count"}}</td><td>{{.Error}}</td>
+</tr>{{end}}
+</table>
+<p><a href="?format=json">json</a></p>
+</body></html>
+{{end}}
+
+{{define "histogram"}}<table>
+<tr><th>count</th><td>{{.Count}}</td><th>mean</th><td>{{.Mean}}</td><th>p50</th><td>{{.P50}}</td><th>p99</th><td>{{.P99}}</td></tr>
+</table>
+<table>
+<tr><th>le</th>{{range .Buckets}}<td>{{.Le}}</td>{{end}}</tr>
+<tr><th>count</th>{{range .Buckets}}<td>{{.Count}}</td>{{end}}</tr>
+</table>
+{{end}}
+
+{{define "detail"}}{{with .Report}}{{template "head" .Name}}
+<p><a href="{{$.Prefix}}/">all pools</a> · <a href="?format=json">json</a></p>
+<h1>{{.Name}}</h1>
+{{if .Error}}<p>error: {{.Error}}</p>{{end}}
+
+{{if $.AllowActions}}<h2>actions</h2>
+<form method="post" action="{{$.Prefix}}/{{.Name}}/drain"><button>drain</button></form>
+<form method="post" action="{{$.Prefix}}/{{.Name}}/recycle">
+rate <input name="rate" size="3" value="1"> interval <input name="interval" size="6" value="100ms"> <button>recycle</button></form>
+<form method="post" action="{{$.Prefix}}/{{.Name}}/resize">
+min <input name="min" size="4" value="{{index .Stats "min_size"}}"> max <input name="max" size="4" value="{{index .Stats "max_size"}}"> <button>resize</button></form>
+{{end}}
+
+<h2>stats</h2>
+<table>{{range $k, $v := .Stats}}<tr><th>{{$k}}</th><td>{{$v}}</td></tr>{{end}}</table>
+
+<h2>wait queue depth (last {{len .QueueDepth}}s, peak {{peak .QueueDepth}})</h2>
+<p class="spark">{{sparkline .QueueDepth}}</p>
+
+<h2>get wait latency</h2>
+{{template "histogram" .Latency.Wait}}
+<h2>hold latency</h2>
+{{template "histogram" .Latency.Hold}}
+
+<h2>resources ({{len .Resources}})</h2>
+<table>
+<tr><th>id</th><th>state</th><th>created</th><th>last used</th><th>uses</th><th>reconnects</th><th>last error</th><th>holder</th></tr>
+{{range .Resources}}<tr>
+<td>{{.ID}}</td><td>{{.State}}</td><td>{{.CreatedAt.Format "15:04:05.000"}}</td><td>{{.LastUsedAt.Format "15:04:05.000"}}</td>
+<td>{{.UseCount}}</td><td>{{.ReconnectCount}}</td><td>{{.LastError}}</td><td>{{.Holder}}</td>
+</tr>{{end}}
+</table>
+
+<h2>recent events</h2>
+<table>
+<tr><th>time</th><th>event</th><th>resource</th><th>detail</th><th>error</th></tr>
+{{range .Events}}<tr>
+<td>{{.Time.Format "15:04:05.000"}}</td><td>{{.Kind}}</td><td>{{.Resource}}</td><td>{{.Detail}}</td><td>{{.Error}}</td>
+</tr>{{end}}
+</table>
+</body></html>
+{{end}}{{end}}
+`))
+
+func render(w http.ResponseWriter, page string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, page, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
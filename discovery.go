@@ -0,0 +1,236 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiscoveredEndpoint 服务发现返回的一个后端
+type DiscoveredEndpoint struct {
+	Addr   string
+	Weight int
+}
+
+// Discovery 服务发现：返回当前后端集合，集合变化时在 Changes 上通知
+// 通知可以合并，收到后以 Endpoints 的返回为准
+type Discovery interface {
+	Endpoints() ([]DiscoveredEndpoint, error)
+	Changes() <-chan struct{}
+}
+
+// notifier 合并式变化通知，发送永不阻塞
+type notifier struct {
+	ch chan struct{}
+}
+
+func newNotifier() notifier { return notifier{ch: make(chan struct{}, 1)} }
+
+func (n notifier) notify() {
+	select {
+	case n.ch <- struct{}{}:
+	default:
+	}
+}
+
+// StaticDiscovery 固定列表，可用 Set 手动替换
+type StaticDiscovery struct {
+	mu        sync.Mutex
+	endpoints []DiscoveredEndpoint
+	changes   notifier
+}
+
+// NewStaticDiscovery 创建固定列表的服务发现
+func NewStaticDiscovery(endpoints ...DiscoveredEndpoint) *StaticDiscovery {
+	return &StaticDiscovery{endpoints: endpoints, changes: newNotifier()}
+}
+
+func (d *StaticDiscovery) Endpoints() ([]DiscoveredEndpoint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DiscoveredEndpoint(nil), d.endpoints...), nil
+}
+
+func (d *StaticDiscovery) Changes() <-chan struct{} { return d.changes.ch }
+
+// Set 替换后端列表并通知
+func (d *StaticDiscovery) Set(endpoints ...DiscoveredEndpoint) {
+	d.mu.Lock()
+	d.endpoints = endpoints
+	d.mu.Unlock()
+	d.changes.notify()
+}
+
+// FileDiscovery 定期轮询文件，内容变化时通知
+// 文件每行一个后端：`地址 [权重]`，空行和 # 开头的行忽略
+type FileDiscovery struct {
+	path    string
+	changes notifier
+	stop    chan struct{}
+	once    sync.Once
+
+	mu        sync.Mutex
+	content   []byte
+	endpoints []DiscoveredEndpoint
+	err       error
+}
+
+// NewFileDiscovery 读取一次文件后每 interval 轮询一次
+func NewFileDiscovery(path string, interval time.Duration) *FileDiscovery {
+	d := &FileDiscovery{path: path, changes: newNotifier(), stop: make(chan struct{})}
+	d.poll()
+	if interval > 0 {
+		go d.run(interval)
+	}
+	return d
+}
+
+func (d *FileDiscovery) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.poll()
+		}
+	}
+}
+
+// poll 读取文件，内容与上次不同时重新解析并通知；读取或解析失败保留上次结果并记录错误
+func (d *FileDiscovery) poll() {
+	content, err := os.ReadFile(d.path)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err != nil {
+		d.err = err
+		return
+	}
+	if d.err == nil && d.content != nil && bytes.Equal(content, d.content) {
+		return
+	}
+	endpoints, err := parseEndpoints(content)
+	if err != nil {
+		d.err = fmt.Errorf("%s: %w", d.path, err)
+		return
+	}
+	d.content, d.endpoints, d.err = content, endpoints, nil
+	d.changes.notify()
+}
+
+func parseEndpoints(content []byte) ([]DiscoveredEndpoint, error) {
+	var endpoints []DiscoveredEndpoint
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		ep := DiscoveredEndpoint{Addr: fields[0]}
+		if len(fields) > 1 {
+			w, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid weight %q", line, fields[1])
+			}
+			ep.Weight = w
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints, scanner.Err()
+}
+
+// Endpoints 返回最近一次成功解析的结果；最近一次读取失败时同时返回该错误
+func (d *FileDiscovery) Endpoints() ([]DiscoveredEndpoint, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DiscoveredEndpoint(nil), d.endpoints...), d.err
+}
+
+func (d *FileDiscovery) Changes() <-chan struct{} { return d.changes.ch }
+
+// Close 停止轮询，重复调用是安全的
+func (d *FileDiscovery) Close() {
+	d.once.Do(func() { close(d.stop) })
+}
+
+// DiscoveryPool 跟随 Discovery 动态调整后端集合的 BalancedPool
+type DiscoveryPool[T any] struct {
+	*BalancedPool[T]
+	discovery Discovery
+	factory   func(addr string) Conn[T]
+	done      chan struct{}
+}
+
+// NewDiscoveryPool 按 Discovery 当前的后端集合创建池，之后每次变化通知都重新对齐
+// factory 为新出现的地址创建连接控制器；首次获取后端集合失败时返回错误
+func NewDiscoveryPool[T any](config BalancedPoolConfig, d Discovery, factory func(addr string) Conn[T]) (*DiscoveryPool[T], error) {
+	endpoints, err := d.Endpoints()
+	if err != nil {
+		return nil, &PoolError{Op: "discover", Pool: config.Name, Kind: ErrNoHealthyBackend, Err: err}
+	}
+	dp := &DiscoveryPool[T]{
+		discovery: d,
+		factory:   factory,
+		done:      make(chan struct{}),
+	}
+	dp.BalancedPool = NewBalancedPool(config, dp.resolve(endpoints))
+	go dp.watch()
+	return dp, nil
+}
+
+// resolve 把发现结果转成 Endpoint，已存在的地址复用原来的连接控制器
+// 同一地址出现多次时只取第一个，否则 Reconcile 会为它建出多个同名后端
+func (dp *DiscoveryPool[T]) resolve(discovered []DiscoveredEndpoint) []Endpoint[T] {
+	existing := make(map[string]Conn[T])
+	if dp.BalancedPool != nil {
+		for _, b := range *dp.backends.Load() {
+			existing[b.name] = b.conn
+		}
+	}
+	endpoints := make([]Endpoint[T], 0, len(discovered))
+	seen := make(map[string]bool, len(discovered))
+	for _, de := range discovered {
+		if seen[de.Addr] {
+			continue
+		}
+		seen[de.Addr] = true
+		conn, ok := existing[de.Addr]
+		if !ok {
+			conn = dp.factory(de.Addr)
+		}
+		endpoints = append(endpoints, Endpoint[T]{Name: de.Addr, Conn: conn, Weight: de.Weight})
+	}
+	return endpoints
+}
+
+// watch 收到变化通知后重新对齐；获取失败或返回空集合时保留当前后端，避免文件写到一半时清空整个池
+func (dp *DiscoveryPool[T]) watch() {
+	defer close(dp.done)
+	for {
+		select {
+		case <-dp.closeCtx.Done():
+			return
+		case <-dp.discovery.Changes():
+			discovered, err := dp.discovery.Endpoints()
+			if err != nil || len(discovered) == 0 {
+				log.Printf("[TemplatePoolByGO] discovery: keeping current backends (endpoints=%d, err=%v)", len(discovered), err)
+				continue
+			}
+			_ = dp.Reconcile(dp.resolve(discovered))
+		}
+	}
+}
+
+// Close 停止跟随 Discovery 并关闭所有后端，重复调用是安全的
+func (dp *DiscoveryPool[T]) Close() {
+	dp.BalancedPool.Close()
+	<-dp.done
+}
@@ -0,0 +1,86 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	closure "github.com/RedHuang-0622/TemplatePoolByGO/util/Closure"
+)
+
+// 定义连接池相关的导出错误，均可用 errors.Is 匹配
+var (
+	ErrPoolBusy         = errors.New("connection pool is busy: maximum capacity reached and wait queue is full")
+	ErrPoolClosed       = errors.New("connection pool is closed")
+	ErrResetFailed      = errors.New("reset connection failed")
+	ErrCreateFailed     = errors.New("create connection failed")
+	ErrValidationFailed = errors.New("connection validation failed")
+	ErrBatchTooLarge    = errors.New("batch size exceeds pool MaxSize")
+
+	ErrWouldExceedDeadline = errors.New("estimated queue wait exceeds context deadline")
+	ErrNoHealthyBackend    = errors.New("no healthy backend available")
+	ErrNoMatchingResource  = errors.New("no idle resource matches selector")
+	ErrLeaseExpired        = errors.New("lease already released or revoked")
+	ErrPoolPaused          = errors.New("connection pool is paused")
+	ErrDrainInProgress     = errors.New("drain already in progress")
+	ErrRecycleInProgress   = errors.New("recycle already in progress")
+	ErrInvalidSize         = errors.New("invalid pool size")
+	ErrExpvarConflict      = errors.New("expvar name already in use")
+	ErrKeyMismatch         = errors.New("resource belongs to another key")
+
+	// Actor 相关错误直接复用 closure 包的哨兵，保证 errors.Is 跨包可用
+	ErrActorStopped = closure.ErrActorStopped
+	ErrInboxFull    = closure.ErrInboxFull
+)
+
+// PoolError 携带失败上下文的错误类型
+// Kind 为上面的哨兵错误之一，Err 为底层原因（可为 nil）
+// errors.Is 同时匹配 Kind 和 Err，errors.As 可继续取出底层原因
+type PoolError struct {
+	Op         string // 操作名：get / put / create / ping ...
+	Pool       string // 池名称（PoolConfig.Name）
+	ResourceID string // 相关资源 ID，可为空
+	Kind       error  // 失败类别（哨兵错误）
+	Err        error  // 底层原因
+}
+
+func (e *PoolError) Error() string {
+	var b strings.Builder
+	if e.Pool != "" {
+		fmt.Fprintf(&b, "pool %q: ", e.Pool)
+	}
+	b.WriteString(e.Op)
+	if e.ResourceID != "" {
+		fmt.Fprintf(&b, " %s", e.ResourceID)
+	}
+	if e.Kind != nil {
+		fmt.Fprintf(&b, ": %v", e.Kind)
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	return b.String()
+}
+
+// Unwrap 返回 Kind 与底层原因，供 errors.Is / errors.As 遍历
+func (e *PoolError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// newError 构造带池名的 PoolError
+func (p *Pool[T]) newError(op, resourceID string, kind, cause error) *PoolError {
+	return &PoolError{
+		Op:         op,
+		Pool:       p.config.Name,
+		ResourceID: resourceID,
+		Kind:       kind,
+		Err:        cause,
+	}
+}
@@ -0,0 +1,176 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind 生命周期事件类型
+type EventKind int
+
+const (
+	EventCreated             EventKind = iota // 新建了一个连接
+	EventClosed                               // 关闭了一个连接，Detail 为原因（Reason* 常量）
+	EventExpanded                             // 一轮扩容开始创建 N 个连接
+	EventShrunk                               // 一轮缩容关闭了 N 个空闲连接
+	EventReconnected                          // Get 时 Ping 失败，重连成功
+	EventPingFailed                           // 心跳或 Get 时 Ping 失败
+	EventWaiterTimedOut                       // 排队等待中 ctx 结束
+	EventRejected                             // 获取请求被直接拒绝，Detail 为 busy / deadline / paused
+	EventConfigChanged                        // Resize 调整了 MinSize / MaxSize
+	EventBreakerStateChanged                  // BalancedPool 的后端下线（open）或恢复（closed）
+	EventPaused                               // Pause
+	EventResumed                              // Resume
+	EventDrained                              // Drain 结束，Err 为 ctx 提前结束的原因
+	EventRecycled                             // RecycleAll 结束，Err 为 ctx 提前结束的原因
+)
+
+// EventClosed 的原因
+const (
+	ReasonPoolClosed  = "pool_closed" // 池已关闭
+	ReasonShrink      = "shrink"      // 缩容
+	ReasonExpired     = "expired"     // 超过 SurviveTime
+	ReasonStale       = "stale"       // Drain / RecycleAll 换代之前创建
+	ReasonPingFailed  = "ping_failed" // Ping 失败且没能重连
+	ReasonResetFailed = "reset_failed"
+	ReasonDiscarded   = "discarded" // Lease.Discard
+	ReasonOverflow    = "overflow"  // 空闲集合放不下
+	ReasonResize      = "resize"    // Resize 调小了 MaxSize
+	ReasonReclaimed   = "reclaimed" // KeyedPool 把名额让给其他 key
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventCreated:
+		return "created"
+	case EventClosed:
+		return "closed"
+	case EventExpanded:
+		return "expanded"
+	case EventShrunk:
+		return "shrunk"
+	case EventReconnected:
+		return "reconnected"
+	case EventPingFailed:
+		return "ping_failed"
+	case EventWaiterTimedOut:
+		return "waiter_timed_out"
+	case EventRejected:
+		return "rejected"
+	case EventConfigChanged:
+		return "config_changed"
+	case EventBreakerStateChanged:
+		return "breaker_state_changed"
+	case EventPaused:
+		return "paused"
+	case EventResumed:
+		return "resumed"
+	case EventDrained:
+		return "drained"
+	case EventRecycled:
+		return "recycled"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 一条生命周期事件
+type Event struct {
+	Time     time.Time
+	Kind     EventKind
+	Resource string // 相关资源 ID，池级事件为空
+	Detail   string // 补充说明，例如关闭原因、Resize 之后的大小
+	N        int64  // EventExpanded / EventShrunk 涉及的连接数
+	Err      error
+}
+
+// recentEvents 每个池保留的最近事件条数
+const recentEvents = 64
+
+// eventBus 保留最近的事件并分发给订阅者；发送不阻塞，订阅者的缓冲满了就丢弃并计数
+type eventBus struct {
+	recent  *ring[Event]
+	dropped atomic.Int64
+
+	mu     sync.RWMutex
+	subs   map[chan Event]struct{}
+	closed bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{recent: newRing[Event](recentEvents), subs: make(map[chan Event]struct{})}
+}
+
+func (b *eventBus) publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.recent.add(e)
+	b.mu.RLock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+	b.mu.RUnlock()
+}
+
+func (b *eventBus) subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, max(buffer, 0))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[ch]; ok {
+				delete(b.subs, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// close 关闭所有订阅的 channel，之后的事件只记入最近事件
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subs {
+		close(ch)
+	}
+	clear(b.subs)
+}
+
+func (p *Pool[T]) emit(kind EventKind, resourceID, detail string, err error) {
+	p.events.publish(Event{Kind: kind, Resource: resourceID, Detail: detail, Err: err})
+}
+
+// emit Actor 侧的 Pool.emit
+func (a *PoolManagerActor[T]) emit(kind EventKind, resourceID, detail string, err error) {
+	a.events.publish(Event{Kind: kind, Resource: resourceID, Detail: detail, Err: err})
+}
+
+// RecentEvents 按时间先后返回最近的生命周期事件（最多 64 条）
+func (p *Pool[T]) RecentEvents() ([]Event, error) {
+	if p.closed.Load() {
+		return nil, p.newError("events", "", ErrPoolClosed, nil)
+	}
+	return p.events.recent.items(), nil
+}
+
+// Subscribe 订阅生命周期事件，返回的 channel 缓冲 buffer 个事件
+// 发送从不阻塞 Actor：订阅者来不及消费时事件被丢弃，计入 Stats 的 events_dropped
+// 调用 cancel 或池关闭时 channel 被关闭；在已关闭的池上订阅得到一个已关闭的 channel
+func (p *Pool[T]) Subscribe(buffer int) (<-chan Event, func()) {
+	return p.events.subscribe(buffer)
+}
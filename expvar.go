@@ -0,0 +1,84 @@
+package pool
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarSlot 一个由本库发布的 expvar 名称，重复发布时只替换数据来源
+type expvarSlot struct {
+	source atomic.Pointer[func() any]
+}
+
+var expvarSlots = struct {
+	sync.Mutex
+	byName map[string]*expvarSlot
+}{byName: make(map[string]*expvarSlot)}
+
+// latencySummary expvar 中延迟分布的摘要
+type latencySummary struct {
+	Count int64  `json:"count"`
+	Mean  string `json:"mean"`
+	P50   string `json:"p50"`
+	P99   string `json:"p99"`
+}
+
+func summarize(h LatencyHistogram) latencySummary {
+	return latencySummary{
+		Count: h.Count,
+		Mean:  h.Mean().String(),
+		P50:   h.Quantile(0.5).String(),
+		P99:   h.Quantile(0.99).String(),
+	}
+}
+
+// expvarSnapshot /debug/vars 中一个池的内容
+type expvarSnapshot struct {
+	Name    string           `json:"name,omitempty"`
+	Closed  bool             `json:"closed"`
+	Stats   map[string]int64 `json:"stats,omitempty"`
+	GetWait *latencySummary  `json:"get_wait,omitempty"`
+	Hold    *latencySummary  `json:"hold,omitempty"`
+}
+
+func (p *Pool[T]) expvarSnapshot() any {
+	snap := expvarSnapshot{Name: p.config.Name}
+	stats, err := p.Stats(p.closeCtx)
+	if err != nil {
+		snap.Closed = true
+		return snap
+	}
+	snap.Stats = stats
+	if lat, err := p.Latency(); err == nil {
+		wait, hold := summarize(lat.Wait), summarize(lat.Hold)
+		snap.GetWait, snap.Hold = &wait, &hold
+	}
+	return snap
+}
+
+// PublishExpvar 把池的 Stats 和延迟摘要以 name 发布到 expvar，/debug/vars 中可见
+// 同一个 name 再次发布（例如重建池之后）时改为展示新的池，不会像 expvar.Publish 那样 panic；
+// name 已被其他代码用 expvar 发布时返回错误。expvar 无法注销，池关闭后该变量显示 closed
+func (p *Pool[T]) PublishExpvar(name string) error {
+	if p.closed.Load() {
+		return p.newError("expvar", "", ErrPoolClosed, nil)
+	}
+	source := p.expvarSnapshot
+
+	expvarSlots.Lock()
+	defer expvarSlots.Unlock()
+	if slot, ok := expvarSlots.byName[name]; ok {
+		slot.source.Store(&source)
+		return nil
+	}
+	if expvar.Get(name) != nil {
+		return p.newError("expvar", "", ErrExpvarConflict, fmt.Errorf("%q already published", name))
+	}
+	slot := &expvarSlot{}
+	slot.source.Store(&source)
+	expvarSlots.byName[name] = slot
+	expvar.Publish(name, expvar.Func(func() any { return (*slot.source.Load())() }))
+	return nil
+}
@@ -0,0 +1,75 @@
+package pool
+
+import "time"
+
+// historySize 每个池保留的采样点数，按默认的 MonitorInterval（10s）约为一小时
+const historySize = 360
+
+// StatsSample 一个监控周期结束时的采样，速率和 p99 只统计本周期
+type StatsSample struct {
+	Time           time.Time
+	Total          int64
+	Idle           int64
+	InUse          int64
+	Waiting        int64
+	Expanding      int64
+	GetsPerSec     float64       // 成功的获取次数 / 秒
+	TimeoutsPerSec float64       // 等待中 ctx 结束的获取次数 / 秒
+	P99Wait        time.Duration // 获取等待的 p99（所在桶的上界）
+}
+
+// statsHistory 监控协程每个周期写入一个采样
+type statsHistory struct {
+	samples *ring[StatsSample]
+
+	// 上一次采样时的累计值，只在监控协程内访问
+	lastTime     time.Time
+	lastWait     LatencyHistogram
+	lastTimeouts int64
+}
+
+func newStatsHistory() statsHistory {
+	return statsHistory{samples: newRing[StatsSample](historySize), lastTime: time.Now()}
+}
+
+// recordHistory 记录一个采样，由 monitorAndAdjust（KeyedPool 子池由共享的监控 ticker）每个周期调用
+func (p *Pool[T]) recordHistory(now time.Time) {
+	h := &p.history
+	wait := p.waitLatency.snapshot()
+	timeouts := p.getTimeouts.Load()
+	delta := wait.sub(h.lastWait)
+	s := StatsSample{
+		Time:      now,
+		Total:     p.totalSize.Load(),
+		Idle:      int64(p.idle.Len()),
+		InUse:     p.inUse.Load(),
+		Waiting:   int64(p.waitQueue.Len()),
+		Expanding: p.expanding.Load(),
+		P99Wait:   delta.Quantile(0.99),
+	}
+	if secs := now.Sub(h.lastTime).Seconds(); secs > 0 {
+		s.GetsPerSec = float64(delta.Count) / secs
+		s.TimeoutsPerSec = float64(timeouts-h.lastTimeouts) / secs
+	}
+	h.lastTime, h.lastWait, h.lastTimeouts = now, wait, timeouts
+	h.samples.add(s)
+}
+
+// History 按时间先后返回最近 d 时间内的采样，d<=0 时返回全部
+// 每个 MonitorInterval 采样一次，最多保留 360 个；MonitorInterval<=0 时没有采样
+func (p *Pool[T]) History(d time.Duration) ([]StatsSample, error) {
+	if p.closed.Load() {
+		return nil, p.newError("history", "", ErrPoolClosed, nil)
+	}
+	samples := p.history.samples.items()
+	if d <= 0 {
+		return samples, nil
+	}
+	since := time.Now().Add(-d)
+	for i, s := range samples {
+		if !s.Time.Before(since) {
+			return samples[i:], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,419 @@
+package pool
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// IdleOrder 空闲连接的取用顺序
+type IdleOrder int
+
+const (
+	// IdleFIFO 先放回的先取出（默认）：流量均匀摊到所有空闲连接上
+	IdleFIFO IdleOrder = iota
+	// IdleLIFO 最近放回的先取出：热连接集中复用，冷连接保持空闲，
+	// 由 shrink 按最早放回的顺序回收，服务端空闲超时也能自然淘汰
+	IdleLIFO
+)
+
+// IdleSet 空闲连接集合
+// Push 总是放到热端；Pop 按实现的取用顺序取；PopOldest 总是取最早放回（最冷）的一个
+// 所有方法都必须并发安全且不阻塞
+type IdleSet[T any] interface {
+	Push(r *Resource[T]) bool // 已满返回 false
+	Pop() (*Resource[T], bool)
+	PopOldest() (*Resource[T], bool)
+	// RemoveIf 遍历空闲连接，摘除 fn 返回 true 的并返回它们
+	// fn 在实现内部的锁中调用，不能回调集合本身
+	RemoveIf(fn func(r *Resource[T]) bool) []*Resource[T]
+	Len() int
+	Cap() int
+}
+
+// newIdleSet 按配置选择实现：开启 Affinity 时用带 key 索引的链表，FIFO 且不分片时用 channel，其余用分片环形缓冲
+func newIdleSet[T any](config PoolConfig, capacity int) IdleSet[T] {
+	order, shards := config.IdleOrder, config.IdleShards
+	if config.Affinity {
+		return NewKeyedIdleSet[T](capacity, order)
+	}
+	if order == IdleFIFO && shards <= 1 {
+		return NewChanIdleSet[T](capacity)
+	}
+	if shards < 1 {
+		shards = 1
+	}
+	return NewShardedIdleSet[T](capacity, shards, order)
+}
+
+// chanIdle 基于 channel 的 FIFO 空闲集合，无锁交接
+type chanIdle[T any] struct {
+	ch chan *resource[T]
+}
+
+// NewChanIdleSet 创建基于 channel 的 FIFO 空闲集合（默认实现）
+func NewChanIdleSet[T any](capacity int) IdleSet[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &chanIdle[T]{ch: make(chan *resource[T], capacity)}
+}
+
+func (c *chanIdle[T]) Push(r *resource[T]) bool {
+	select {
+	case c.ch <- r:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *chanIdle[T]) Pop() (*resource[T], bool) {
+	select {
+	case r := <-c.ch:
+		return r, true
+	default:
+		return nil, false
+	}
+}
+
+// PopOldest channel 本身就是 FIFO，与 Pop 相同
+func (c *chanIdle[T]) PopOldest() (*resource[T], bool) { return c.Pop() }
+func (c *chanIdle[T]) Len() int                        { return len(c.ch) }
+func (c *chanIdle[T]) Cap() int                        { return cap(c.ch) }
+
+// RemoveIf channel 无法原地遍历：取出当前的全部元素逐个判断，保留的按原顺序放回
+// 遍历期间被取出的连接对并发的 Pop 不可见；放回时已被并发 Push 占满的，也一并返回由调用方处理
+func (c *chanIdle[T]) RemoveIf(fn func(r *resource[T]) bool) []*resource[T] {
+	var removed, kept []*resource[T]
+	for n := len(c.ch); n > 0; n-- {
+		r, ok := c.Pop()
+		if !ok {
+			break
+		}
+		if fn(r) {
+			removed = append(removed, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	for _, r := range kept {
+		if !c.Push(r) {
+			removed = append(removed, r)
+		}
+	}
+	return removed
+}
+
+// ringIdle 互斥锁保护的环形缓冲，尾部为热端：LIFO 取尾部，FIFO 取头部，PopOldest 总是取头部
+type ringIdle[T any] struct {
+	mu    sync.Mutex
+	items []*resource[T]
+	head  int // 最早放回的元素下标
+	size  int
+	order IdleOrder
+}
+
+func newRingIdle[T any](capacity int, order IdleOrder) *ringIdle[T] {
+	return &ringIdle[T]{items: make([]*resource[T], capacity), order: order}
+}
+
+func (s *ringIdle[T]) Push(r *resource[T]) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == len(s.items) {
+		return false
+	}
+	s.items[(s.head+s.size)%len(s.items)] = r
+	s.size++
+	return true
+}
+
+func (s *ringIdle[T]) Pop() (*resource[T], bool) {
+	if s.order != IdleLIFO {
+		return s.PopOldest()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return nil, false
+	}
+	s.size--
+	i := (s.head + s.size) % len(s.items)
+	r := s.items[i]
+	s.items[i] = nil
+	return r, true
+}
+
+func (s *ringIdle[T]) PopOldest() (*resource[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return nil, false
+	}
+	r := s.items[s.head]
+	s.items[s.head] = nil
+	s.head = (s.head + 1) % len(s.items)
+	s.size--
+	return r, true
+}
+
+// RemoveIf 原地压缩，保留的元素保持原有先后顺序
+func (s *ringIdle[T]) RemoveIf(fn func(r *resource[T]) bool) []*resource[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed []*resource[T]
+	n := len(s.items)
+	kept := 0
+	for i := 0; i < s.size; i++ {
+		idx := (s.head + i) % n
+		r := s.items[idx]
+		s.items[idx] = nil
+		if fn(r) {
+			removed = append(removed, r)
+			continue
+		}
+		s.items[(s.head+kept)%n] = r
+		kept++
+	}
+	s.size = kept
+	return removed
+}
+
+// oldest 返回头部元素的放回时间，空时 ok=false
+func (s *ringIdle[T]) oldest() (updated int64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return 0, false
+	}
+	return s.items[s.head].updateTime.Load(), true
+}
+
+func (s *ringIdle[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *ringIdle[T]) Cap() int { return len(s.items) }
+
+// shardedIdle 分片空闲集合：每个分片一把锁一个环形缓冲，Push/Pop 轮询起始分片以分散锁竞争
+// 取用顺序只在分片内严格成立，跨分片是近似的
+type shardedIdle[T any] struct {
+	shards  []*ringIdle[T]
+	pushIdx atomic.Uint64
+	popIdx  atomic.Uint64
+	size    atomic.Int64
+}
+
+// NewShardedIdleSet 创建 shards 个分片的空闲集合，总容量不小于 capacity
+func NewShardedIdleSet[T any](capacity, shards int, order IdleOrder) IdleSet[T] {
+	if shards < 1 {
+		shards = 1
+	}
+	per := (capacity + shards - 1) / shards
+	if per < 1 {
+		per = 1
+	}
+	s := &shardedIdle[T]{shards: make([]*ringIdle[T], shards)}
+	for i := range s.shards {
+		s.shards[i] = newRingIdle[T](per, order)
+	}
+	return s
+}
+
+func (s *shardedIdle[T]) Push(r *resource[T]) bool {
+	// 先加计数再放入、先取出再减计数：size 只会高估，Pop 的空集合快速判断不会漏掉元素
+	s.size.Add(1)
+	n := uint64(len(s.shards))
+	start := s.pushIdx.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if s.shards[(start+i)%n].Push(r) {
+			return true
+		}
+	}
+	s.size.Add(-1)
+	return false
+}
+
+func (s *shardedIdle[T]) Pop() (*resource[T], bool) {
+	if s.size.Load() <= 0 {
+		return nil, false
+	}
+	n := uint64(len(s.shards))
+	start := s.popIdx.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r, ok := s.shards[(start+i)%n].Pop(); ok {
+			s.size.Add(-1)
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// PopOldest 比较各分片头部的放回时间，取最早的；比较与摘除之间被抢走时退化为任取一个分片的头部
+func (s *shardedIdle[T]) PopOldest() (*resource[T], bool) {
+	if s.size.Load() <= 0 {
+		return nil, false
+	}
+	best := -1
+	var bestAt int64
+	for i, sh := range s.shards {
+		if at, ok := sh.oldest(); ok && (best < 0 || at < bestAt) {
+			best, bestAt = i, at
+		}
+	}
+	if best >= 0 {
+		if r, ok := s.shards[best].PopOldest(); ok {
+			s.size.Add(-1)
+			return r, true
+		}
+	}
+	for _, sh := range s.shards {
+		if r, ok := sh.PopOldest(); ok {
+			s.size.Add(-1)
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+func (s *shardedIdle[T]) RemoveIf(fn func(r *resource[T]) bool) []*resource[T] {
+	var removed []*resource[T]
+	for _, sh := range s.shards {
+		removed = append(removed, sh.RemoveIf(fn)...)
+	}
+	s.size.Add(-int64(len(removed)))
+	return removed
+}
+
+func (s *shardedIdle[T]) Len() int {
+	if n := s.size.Load(); n > 0 {
+		return int(n)
+	}
+	return 0
+}
+
+func (s *shardedIdle[T]) Cap() int {
+	return len(s.shards) * s.shards[0].Cap()
+}
+
+// KeyedIdleSet 支持按亲和 key 取用的空闲集合，供 Pool.GetAffinity 使用
+type KeyedIdleSet[T any] interface {
+	IdleSet[T]
+	// PopKey 取出最近一次服务 key 的空闲连接中最近放回的一个
+	PopKey(key string) (*Resource[T], bool)
+}
+
+// keyedNode 同时挂在全局顺序链表和所属 key 的链表上，两边都能 O(1) 摘除
+type keyedNode[T any] struct {
+	r     *resource[T]
+	key   string
+	all   *list.Element
+	byKey *list.Element
+}
+
+// keyedIdle 互斥锁保护的双向链表，另按 affinityKey 建索引；尾部为热端
+type keyedIdle[T any] struct {
+	mu    sync.Mutex
+	all   *list.List // *keyedNode，头部最早放回
+	byKey map[string]*list.List
+	cap   int
+	order IdleOrder
+}
+
+// NewKeyedIdleSet 创建按亲和 key 建索引的空闲集合
+func NewKeyedIdleSet[T any](capacity int, order IdleOrder) KeyedIdleSet[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &keyedIdle[T]{all: list.New(), byKey: make(map[string]*list.List), cap: capacity, order: order}
+}
+
+func (s *keyedIdle[T]) Push(r *resource[T]) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.all.Len() >= s.cap {
+		return false
+	}
+	n := &keyedNode[T]{r: r, key: r.affinityKey}
+	n.all = s.all.PushBack(n)
+	if n.key != "" {
+		l := s.byKey[n.key]
+		if l == nil {
+			l = list.New()
+			s.byKey[n.key] = l
+		}
+		n.byKey = l.PushBack(n)
+	}
+	return true
+}
+
+// remove 调用方需持有锁
+func (s *keyedIdle[T]) remove(n *keyedNode[T]) *resource[T] {
+	s.all.Remove(n.all)
+	if n.byKey != nil {
+		l := s.byKey[n.key]
+		l.Remove(n.byKey)
+		if l.Len() == 0 {
+			delete(s.byKey, n.key)
+		}
+	}
+	return n.r
+}
+
+func (s *keyedIdle[T]) Pop() (*resource[T], bool) {
+	if s.order != IdleLIFO {
+		return s.PopOldest()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.all.Back()
+	if e == nil {
+		return nil, false
+	}
+	return s.remove(e.Value.(*keyedNode[T])), true
+}
+
+func (s *keyedIdle[T]) PopOldest() (*resource[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.all.Front()
+	if e == nil {
+		return nil, false
+	}
+	return s.remove(e.Value.(*keyedNode[T])), true
+}
+
+func (s *keyedIdle[T]) PopKey(key string) (*resource[T], bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := s.byKey[key]
+	if l == nil {
+		return nil, false
+	}
+	return s.remove(l.Back().Value.(*keyedNode[T])), true
+}
+
+func (s *keyedIdle[T]) RemoveIf(fn func(r *resource[T]) bool) []*resource[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed []*resource[T]
+	for e := s.all.Front(); e != nil; {
+		next := e.Next()
+		if n := e.Value.(*keyedNode[T]); fn(n.r) {
+			removed = append(removed, s.remove(n))
+		}
+		e = next
+	}
+	return removed
+}
+
+func (s *keyedIdle[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.all.Len()
+}
+
+func (s *keyedIdle[T]) Cap() int { return s.cap }
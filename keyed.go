@@ -0,0 +1,345 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyedPoolConfig KeyedPool 的配置
+type KeyedPoolConfig struct {
+	PoolConfig                 // 每个 key 的子池配置，MaxSize 为单个 key 的上限
+	MaxTotalSize int64         // 所有 key 的连接总数上限，<=0 不限
+	KeyIdleTTL   time.Duration // 子池没有在用连接和等待者、且超过该时长没有 Get 时整体关闭回收，<=0 不回收
+}
+
+// KeyedPool 按 key 懒创建子池，例如每个 Redis 分片 / MySQL 副本一个池
+// 所有子池共用一个 Actor 事件循环和一组 ticker（监控、心跳、回收），并共享 MaxTotalSize 预算
+type KeyedPool[K comparable, T any] struct {
+	config  KeyedPoolConfig
+	factory func(K) Conn[T]
+	loop    *sharedLoop
+	budget  *sizeBudget[T] // MaxTotalSize<=0 时为 nil
+
+	mu    sync.RWMutex
+	pools map[K]*keyedEntry[T]
+
+	closeCtx context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	closed   atomic.Bool
+
+	evicted   atomic.Int64 // 因 KeyIdleTTL 回收的子池数
+	reclaimed atomic.Int64 // 为其他 key 腾出预算而关闭的空闲连接数
+}
+
+type keyedEntry[T any] struct {
+	pool     *Pool[T]
+	lastUsed atomic.Int64 // 最近一次 Get 的时间（UnixNano）
+}
+
+// NewKeyedPool 创建按 key 分池的连接池，factory 为每个新 key 提供连接控制器
+func NewKeyedPool[K comparable, T any](config KeyedPoolConfig, factory func(K) Conn[T]) *KeyedPool[K, T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	kp := &KeyedPool[K, T]{
+		config:   config,
+		factory:  factory,
+		loop:     newSharedLoop(),
+		pools:    make(map[K]*keyedEntry[T]),
+		closeCtx: ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	if config.MaxTotalSize > 0 {
+		kp.budget = &sizeBudget[T]{max: config.MaxTotalSize}
+		kp.budget.pools.Store(&[]*Pool[T]{})
+	}
+	go kp.run()
+	return kp
+}
+
+// entry 返回 key 对应的子池，不存在时创建
+func (kp *KeyedPool[K, T]) entry(key K) (*keyedEntry[T], error) {
+	kp.mu.RLock()
+	e, ok := kp.pools[key]
+	kp.mu.RUnlock()
+	if ok {
+		return e, nil
+	}
+
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.closed.Load() {
+		return nil, kp.newError("get", ErrPoolClosed)
+	}
+	if e, ok := kp.pools[key]; ok {
+		return e, nil
+	}
+	c := kp.config.PoolConfig
+	c.Name = fmt.Sprintf("%s[%v]", kp.config.Name, key)
+	e = &keyedEntry[T]{
+		pool: newPool(c, kp.factory(key), poolOptions[T]{
+			key: key, budget: kp.budget, loop: kp.loop, noTickers: true, register: kp.registerBudget,
+		}),
+	}
+	e.lastUsed.Store(time.Now().UnixNano())
+	kp.pools[key] = e
+	kp.publishBudget()
+	return e, nil
+}
+
+// registerBudget 在新子池 preInit 之前把它加入预算，否则它预建的连接不计入总数；调用方需持有写锁
+func (kp *KeyedPool[K, T]) registerBudget(p *Pool[T]) {
+	if kp.budget == nil {
+		return
+	}
+	pools := append(append([]*Pool[T](nil), *kp.budget.pools.Load()...), p)
+	kp.budget.pools.Store(&pools)
+}
+
+// publishBudget 以写时复制发布当前子池列表，调用方需持有写锁
+func (kp *KeyedPool[K, T]) publishBudget() {
+	if kp.budget == nil {
+		return
+	}
+	pools := make([]*Pool[T], 0, len(kp.pools))
+	for _, e := range kp.pools {
+		pools = append(pools, e.pool)
+	}
+	kp.budget.pools.Store(&pools)
+}
+
+func (kp *KeyedPool[K, T]) newError(op string, kind error) *PoolError {
+	return &PoolError{Op: op, Pool: kp.config.Name, Kind: kind}
+}
+
+// Get 从 key 对应的子池获取连接，子池不存在时懒创建
+// 全局预算已满且该 key 没有空闲连接时，先关闭其他 key 最冷的一个空闲连接腾出预算
+func (kp *KeyedPool[K, T]) Get(ctx context.Context, key K) (*Resource[T], error) {
+	for {
+		if kp.closed.Load() {
+			return nil, kp.newError("get", ErrPoolClosed)
+		}
+		e, err := kp.entry(key)
+		if err != nil {
+			return nil, err
+		}
+		e.lastUsed.Store(time.Now().UnixNano())
+		if e.pool.idle.Len() == 0 && kp.budget.exhausted() {
+			kp.reclaim(e.pool, 1)
+		}
+		r, err := e.pool.Get(ctx)
+		// 子池恰好在 Get 前被 TTL 回收：重新建一个再取
+		if err != nil && errors.Is(err, ErrPoolClosed) && !kp.closed.Load() {
+			continue
+		}
+		return r, err
+	}
+}
+
+// Put 把连接归还给创建它的子池
+// key 与连接所属的 key 不一致时返回 ErrKeyMismatch，连接不归还，调用方仍持有它
+// 子池已被 KeyIdleTTL 回收或 KeyedPool 已关闭时，由该子池关闭连接并返回 ErrPoolClosed
+func (kp *KeyedPool[K, T]) Put(key K, res *Resource[T]) error {
+	if res == nil {
+		return nil
+	}
+	if res.owner == nil {
+		return kp.newError("put", ErrPoolClosed)
+	}
+	if owner, ok := res.owner.key.(K); !ok || owner != key {
+		return &PoolError{Op: "put", Pool: kp.config.Name, ResourceID: res.ID, Kind: ErrKeyMismatch,
+			Err: fmt.Errorf("resource belongs to key %v, put under %v", res.owner.key, key)}
+	}
+	return res.owner.Put(res)
+}
+
+// reclaim 从除 except 之外有空闲、没人排队的子池里关闭最多 n 个最冷的空闲连接
+// 先回收超出 MinSize 的部分；不够时 MinSize 也让位，否则预算被其他 key 的 MinSize 占满时新 key 一个连接都拿不到
+func (kp *KeyedPool[K, T]) reclaim(except *Pool[T], n int64) {
+	kp.mu.RLock()
+	defer kp.mu.RUnlock()
+	for _, keepMin := range []bool{true, false} {
+		for _, e := range kp.pools {
+			if n <= 0 {
+				return
+			}
+			p := e.pool
+			if p == except || p.waitQueue.Len() > 0 || (keepMin && p.totalSize.Load() <= p.minSize.Load()) {
+				continue
+			}
+			if r, ok := p.idle.PopOldest(); ok {
+				p.destroy(r, ReasonReclaimed)
+				kp.reclaimed.Add(1)
+				n--
+			}
+		}
+	}
+}
+
+// run 所有子池共用的一组 ticker
+func (kp *KeyedPool[K, T]) run() {
+	defer close(kp.done)
+	monitor := newOptionalTicker(kp.config.MonitorInterval)
+	defer monitor.Stop()
+	ping := newOptionalTicker(kp.config.PingInterval)
+	defer ping.Stop()
+	evictEvery := kp.config.KeyIdleTTL / 2
+	if evictEvery <= 0 {
+		evictEvery = kp.config.KeyIdleTTL
+	}
+	evict := newOptionalTicker(evictEvery)
+	defer evict.Stop()
+
+	for {
+		select {
+		case <-kp.closeCtx.Done():
+			return
+		case now := <-monitor.C:
+			var demand int64
+			for _, p := range kp.snapshot() {
+				p.recordHistory(now)
+				p.adjust()
+				demand += p.waitQueue.Demand()
+			}
+			// 预算用尽时有 key 在排队：从其他 key 回收空闲连接，让排队的 key 能扩容
+			if demand > 0 && kp.budget.exhausted() {
+				kp.reclaim(nil, demand)
+			}
+		case <-ping.C:
+			for _, p := range kp.snapshot() {
+				p.doPingRound()
+			}
+		case <-evict.C:
+			kp.evictIdle()
+		}
+	}
+}
+
+func (kp *KeyedPool[K, T]) snapshot() []*Pool[T] {
+	kp.mu.RLock()
+	defer kp.mu.RUnlock()
+	pools := make([]*Pool[T], 0, len(kp.pools))
+	for _, e := range kp.pools {
+		pools = append(pools, e.pool)
+	}
+	return pools
+}
+
+// evictIdle 关闭超过 KeyIdleTTL 没有 Get、且没有在用连接和等待者的子池
+func (kp *KeyedPool[K, T]) evictIdle() {
+	deadline := time.Now().Add(-kp.config.KeyIdleTTL).UnixNano()
+	var victims []*Pool[T]
+	kp.mu.Lock()
+	for key, e := range kp.pools {
+		if e.lastUsed.Load() > deadline || e.pool.inUse.Load() > 0 || e.pool.waitQueue.Len() > 0 {
+			continue
+		}
+		delete(kp.pools, key)
+		victims = append(victims, e.pool)
+	}
+	if len(victims) > 0 {
+		kp.publishBudget()
+	}
+	kp.mu.Unlock()
+
+	for _, p := range victims {
+		p.Close()
+		kp.evicted.Add(1)
+	}
+}
+
+// KeyStats 返回每个 key 子池的统计
+func (kp *KeyedPool[K, T]) KeyStats(ctx context.Context) (map[K]map[string]int64, error) {
+	if kp.closed.Load() {
+		return nil, kp.newError("stats", ErrPoolClosed)
+	}
+	kp.mu.RLock()
+	defer kp.mu.RUnlock()
+	out := make(map[K]map[string]int64, len(kp.pools))
+	for key, e := range kp.pools {
+		stats, err := e.pool.Stats(ctx)
+		if err != nil {
+			continue // 正在被回收
+		}
+		out[key] = stats
+	}
+	return out, nil
+}
+
+// KeyHistory 返回 key 对应子池最近 d 内的 History 采样，key 不存在（未创建或已被回收）时返回 nil
+func (kp *KeyedPool[K, T]) KeyHistory(key K, d time.Duration) ([]StatsSample, error) {
+	if kp.closed.Load() {
+		return nil, kp.newError("history", ErrPoolClosed)
+	}
+	kp.mu.RLock()
+	e, ok := kp.pools[key]
+	kp.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	return e.pool.History(d)
+}
+
+// Stats 所有 key 子池的统计求和，另附 key 数、回收的子池数和为腾预算关闭的连接数
+func (kp *KeyedPool[K, T]) Stats(ctx context.Context) (map[string]int64, error) {
+	perKey, err := kp.KeyStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	total := make(map[string]int64)
+	for _, stats := range perKey {
+		for k, v := range stats {
+			total[k] += v
+		}
+	}
+	total["keys"] = int64(len(perKey))
+	total["evicted_keys"] = kp.evicted.Load()
+	total["reclaimed_idle"] = kp.reclaimed.Load()
+	return total, nil
+}
+
+// Close 关闭所有子池和共享的事件循环，重复调用是安全的
+func (kp *KeyedPool[K, T]) Close() {
+	if !kp.closed.CompareAndSwap(false, true) {
+		return
+	}
+	kp.cancel()
+	<-kp.done
+	kp.mu.Lock()
+	pools := kp.pools
+	kp.pools = make(map[K]*keyedEntry[T])
+	kp.mu.Unlock()
+	for _, e := range pools {
+		e.pool.Close()
+	}
+	kp.loop.StopAndWait()
+}
+
+// IsClosed 返回 KeyedPool 是否已关闭
+func (kp *KeyedPool[K, T]) IsClosed() bool {
+	return kp.closed.Load()
+}
+
+// optionalTicker interval<=0 时 C 为 nil，永远不触发
+type optionalTicker struct {
+	t *time.Ticker
+	C <-chan time.Time
+}
+
+func newOptionalTicker(interval time.Duration) optionalTicker {
+	if interval <= 0 {
+		return optionalTicker{}
+	}
+	t := time.NewTicker(interval)
+	return optionalTicker{t: t, C: t.C}
+}
+
+func (o optionalTicker) Stop() {
+	if o.t != nil {
+		o.t.Stop()
+	}
+}
@@ -0,0 +1,177 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Labels 资源标签，例如 {"mode": "ro", "db": "orders"}
+type Labels map[string]string
+
+// Match 是否包含 want 中的全部键值，可直接用作 GetMatching 的 selector
+func (l Labels) Match(want Labels) bool {
+	for k, v := range want {
+		if got, ok := l[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Labels 返回资源标签的副本
+func (r *Resource[T]) Labels() Labels { return maps.Clone(r.labels) }
+
+// SetLabel 设置一个标签，例如执行 USE 之后记下当前库；只能由持有该资源的调用方在 Put 之前调用
+func (r *Resource[T]) SetLabel(key, value string) {
+	if r.labels == nil {
+		r.labels = make(Labels)
+	}
+	r.labels[key] = value
+}
+
+// LabeledConn Conn 的可选扩展：创建连接时一并给出它的标签，例如只读副本、指定库
+// 实现之后扩容和预建的连接都用 selector=nil 创建并记下返回的标签；
+// GetMatching 没有匹配的空闲资源时传入它的 selector，要求新建一个满足它的连接，做不到时返回错误
+type LabeledConn[T any] interface {
+	Conn[T]
+	CreateLabeled(selector func(Labels) bool) (T, Labels, error)
+}
+
+// createLabeled 新建连接；连接控制器没有实现 LabeledConn 时标签为空
+func createLabeled[T any](cc Conn[T], selector func(Labels) bool) (T, Labels, error) {
+	if lc, ok := cc.(LabeledConn[T]); ok {
+		conn, labels, err := lc.CreateLabeled(selector)
+		return conn, maps.Clone(labels), err
+	}
+	conn, err := cc.Create()
+	return conn, nil, err
+}
+
+// GetMatching 取一个标签满足 selector 的空闲资源（selector 不能修改传入的标签）
+// 没有匹配的空闲资源时，若连接控制器实现了 LabeledConn，则让它新建一个满足 selector 的连接：
+// 有空位直接新建，池满时取一个不匹配的资源关闭后就地替换；否则返回 ErrNoMatchingResource
+func (p *Pool[T]) GetMatching(ctx context.Context, selector func(Labels) bool) (*resource[T], error) {
+	start := time.Now()
+	if p.closed.Load() {
+		return nil, p.newError("getmatching", "", ErrPoolClosed, nil)
+	}
+	if err := p.waitResume(ctx, "getmatching"); err != nil {
+		return nil, err
+	}
+	for {
+		r, ok := p.popMatching(selector)
+		if !ok {
+			break
+		}
+		if res, err := p.validateAndReturn(r); err == nil {
+			p.waitLatency.observe(time.Since(start))
+			return res, nil
+		}
+	}
+
+	if _, ok := p.connControl.(LabeledConn[T]); !ok {
+		return nil, p.newError("getmatching", "", ErrNoMatchingResource, nil)
+	}
+	if p.reserve() {
+		gen := p.gen.Load()
+		conn, labels, err := p.createMatching(selector)
+		if err != nil {
+			p.expanding.Add(-1)
+			return nil, err
+		}
+		p.totalSize.Add(1)
+		p.expanding.Add(-1)
+		p.inUse.Add(1)
+		res := newResource(fmt.Sprintf("lbl-%d", time.Now().UnixNano()), conn, p, gen)
+		res.labels = labels
+		p.track(res)
+		p.emit(EventCreated, res.ID, "", nil)
+		p.checkout(res)
+		p.waitLatency.observe(time.Since(start))
+		return res, nil
+	}
+
+	// 池已满：拿任意一个资源（空闲的或等别人归还），把它的名额换成匹配的新连接；get 已经计入获取等待
+	res, err := p.get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if selector(res.labels) {
+		return res, nil
+	}
+	conn, labels, err := p.createMatching(selector)
+	if err != nil {
+		// 旧连接还在，原样交还
+		p.Put(res)
+		return nil, err
+	}
+	p.connControl.Close(res.Conn)
+	res.Conn = conn
+	res.createTime.Store(time.Now().UnixNano())
+	res.affinityKey = ""
+	res.labels = labels
+	res.gen = p.gen.Load()
+	return res, nil
+}
+
+// createMatching 让 LabeledConn 新建一个满足 selector 的连接；返回的标签不满足时关闭它，视为无法新建
+func (p *Pool[T]) createMatching(selector func(Labels) bool) (T, Labels, error) {
+	conn, labels, err := createLabeled(p.connControl, selector)
+	if err != nil {
+		return conn, nil, p.newError("getmatching", "", ErrCreateFailed, err)
+	}
+	if !selector(labels) {
+		p.connControl.Close(conn)
+		var zero T
+		return zero, nil, p.newError("getmatching", "", ErrNoMatchingResource, fmt.Errorf("created labels %v", labels))
+	}
+	return conn, labels, nil
+}
+
+// popMatching 从空闲集合摘出第一个匹配 selector 的资源
+func (p *Pool[T]) popMatching(selector func(Labels) bool) (*resource[T], bool) {
+	taken := false
+	removed := p.removeIdle(func(r *resource[T]) bool {
+		if taken || !selector(r.labels) {
+			return false
+		}
+		taken = true
+		return true
+	})
+	// chanIdle 放回时被并发 Push 占满的资源也会出现在 removed 里（排在匹配项之后），交还给池
+	if !taken {
+		for _, r := range removed {
+			p.handBack(r)
+		}
+		return nil, false
+	}
+	for _, r := range removed[1:] {
+		p.handBack(r)
+	}
+	return removed[0], true
+}
+
+// recreate 重连时按原有标签新建，保证替换后的连接仍然符合标签
+func (p *Pool[T]) recreate(r *resource[T]) (T, error) {
+	var selector func(Labels) bool
+	if want := r.labels; len(want) > 0 {
+		selector = func(l Labels) bool { return l.Match(want) }
+	}
+	conn, _, err := createLabeled(p.connControl, selector)
+	return conn, err
+}
+
+// reserve 在 MaxSize 和全局预算内为一个新连接占位，成功后调用方负责 expanding.Add(-1)
+// Drain 期间不占位：和 Actor 一样不新建连接，调用方退回排队等 Drain 结束后重建的连接
+func (p *Pool[T]) reserve() bool {
+	if p.draining.Load() {
+		return false
+	}
+	if p.totalSize.Load()+p.expanding.Add(1) > p.maxSize.Load() || !p.budget.allow() {
+		p.expanding.Add(-1)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package pool
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBounds 延迟直方图各桶的上界，最后还有一个无上界的桶
+var latencyBounds = [...]time.Duration{
+	50 * time.Microsecond, 100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond,
+	25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+	500 * time.Millisecond, time.Second, 2500 * time.Millisecond, 5 * time.Second,
+}
+
+// latencyRecorder 固定分桶的无锁直方图
+type latencyRecorder struct {
+	counts [len(latencyBounds) + 1]atomic.Int64
+	sum    atomic.Int64
+}
+
+func (l *latencyRecorder) observe(d time.Duration) {
+	i := sort.Search(len(latencyBounds), func(i int) bool { return d <= latencyBounds[i] })
+	l.counts[i].Add(1)
+	l.sum.Add(int64(d))
+}
+
+func (l *latencyRecorder) snapshot() LatencyHistogram {
+	h := LatencyHistogram{
+		Bounds: latencyBounds[:],
+		Counts: make([]int64, len(l.counts)),
+		Sum:    time.Duration(l.sum.Load()),
+	}
+	for i := range l.counts {
+		h.Counts[i] = l.counts[i].Load()
+		h.Count += h.Counts[i]
+	}
+	return h
+}
+
+// sub 返回 h 相对更早的快照 prev 的增量；prev 为空时返回 h
+func (h LatencyHistogram) sub(prev LatencyHistogram) LatencyHistogram {
+	if prev.Counts == nil {
+		return h
+	}
+	d := LatencyHistogram{Bounds: h.Bounds, Counts: make([]int64, len(h.Counts)), Count: h.Count - prev.Count, Sum: h.Sum - prev.Sum}
+	for i := range h.Counts {
+		d.Counts[i] = h.Counts[i] - prev.Counts[i]
+	}
+	return d
+}
+
+// LatencyHistogram 延迟分布快照，自池创建起累计
+type LatencyHistogram struct {
+	Bounds []time.Duration // 各桶上界（含），只读
+	Counts []int64         // 各桶计数，比 Bounds 多一个无上界的桶
+	Count  int64
+	Sum    time.Duration
+}
+
+// Mean 平均延迟
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile 返回分位数 q（0~1）所在桶的上界；落在最后一个桶时返回最大的上界
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int64(q * float64(h.Count))
+	if rank >= h.Count {
+		rank = h.Count - 1
+	}
+	var seen int64
+	for i, c := range h.Counts {
+		seen += c
+		if seen > rank && i < len(h.Bounds) {
+			return h.Bounds[i]
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// LatencyStats 池的两个延迟分布
+type LatencyStats struct {
+	Wait LatencyHistogram // 获取等待：从调用 Get / TryGet / GetN / GetMatching 等到拿到连接，只统计成功的，GetN 按连接数计
+	Hold LatencyHistogram // 持有时长：从借出到 Put 归还
+}
+
+// Latency 返回获取等待和持有时长的延迟分布
+func (p *Pool[T]) Latency() (LatencyStats, error) {
+	if p.closed.Load() {
+		return LatencyStats{}, p.newError("latency", "", ErrPoolClosed, nil)
+	}
+	return LatencyStats{Wait: p.waitLatency.snapshot(), Hold: p.holdLatency.snapshot()}, nil
+}
@@ -0,0 +1,134 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type leaseState int
+
+const (
+	leaseActive leaseState = iota
+	leaseReleased
+	leaseRevoked
+)
+
+// Lease 带持有期限的资源租约：超过期限没有 Renew 时池子强制收回，
+// 关闭连接并把名额让给等待者，避免卡死的 goroutine 长期占着容量
+type Lease[T any] struct {
+	p        *Pool[T]
+	res      *resource[T]
+	expired  chan struct{}
+	mu       sync.Mutex
+	state    leaseState
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// Acquire 获取资源并返回租约，maxHold<=0 表示不限时
+func (p *Pool[T]) Acquire(ctx context.Context, maxHold time.Duration) (*Lease[T], error) {
+	if p.closed.Load() {
+		return nil, p.newError("acquire", "", ErrPoolClosed, nil)
+	}
+	res, err := p.get(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	l := &Lease[T]{p: p, res: res, expired: make(chan struct{})}
+	if maxHold > 0 {
+		l.deadline = time.Now().Add(maxHold)
+		l.timer = time.AfterFunc(maxHold, l.revoke)
+	}
+	return l, nil
+}
+
+// Conn 返回租约持有的连接；租约被收回后连接已关闭
+func (l *Lease[T]) Conn() T { return l.res.Conn }
+
+// ID 返回资源 ID
+func (l *Lease[T]) ID() string { return l.res.ID }
+
+// Expired 租约被强制收回时关闭；正常 Release / Discard 不会关闭
+func (l *Lease[T]) Expired() <-chan struct{} { return l.expired }
+
+// Renew 从现在起把期限延长为 d；租约已结束时返回 ErrLeaseExpired
+func (l *Lease[T]) Renew(d time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state != leaseActive {
+		return l.p.newError("renew", l.res.ID, ErrLeaseExpired, nil)
+	}
+	if d <= 0 {
+		return nil
+	}
+	l.deadline = time.Now().Add(d)
+	if l.timer == nil {
+		l.timer = time.AfterFunc(d, l.revoke)
+	} else {
+		l.timer.Reset(d)
+	}
+	return nil
+}
+
+// end 把租约切到终态，返回 false 表示已经结束过
+func (l *Lease[T]) end(state leaseState) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state != leaseActive {
+		return false
+	}
+	l.state = state
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	return true
+}
+
+// Release 归还资源；租约已被收回或已结束时什么都不做
+func (l *Lease[T]) Release() error {
+	if !l.end(leaseReleased) {
+		return nil
+	}
+	return l.p.put(l.res)
+}
+
+// Discard 持有者判定连接已损坏：关闭连接并释放名额，err 非空时回调 OnUnhealthy
+func (l *Lease[T]) Discard(err error) {
+	if !l.end(leaseReleased) {
+		return
+	}
+	l.res.setErr(err)
+	l.p.discard(l.res)
+	if err != nil && l.p.config.OnUnhealthy != nil {
+		l.p.config.OnUnhealthy(l.p.newError("discard", l.res.ID, ErrValidationFailed, err))
+	}
+}
+
+// revoke 定时器到期：Renew 与定时器触发竞争时以 deadline 为准
+func (l *Lease[T]) revoke() {
+	l.mu.Lock()
+	if l.state != leaseActive || time.Now().Before(l.deadline) {
+		l.mu.Unlock()
+		return
+	}
+	l.state = leaseRevoked
+	l.mu.Unlock()
+
+	l.p.revokedLeases.Add(1)
+	l.p.discard(l.res)
+	close(l.expired)
+}
+
+// discard 关闭一个借出中的资源并释放名额，由 Actor 按需补充给等待者
+func (p *Pool[T]) discard(res *resource[T]) {
+	p.inUse.Add(-1)
+	if p.closed.Load() {
+		p.destroy(res, ReasonDiscarded)
+		return
+	}
+	_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		a.destroy(res, ReasonDiscarded)
+		a.checkAndAdjust(s)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -13,25 +14,59 @@ import (
 )
 
 type Pool[T any] struct {
-	resources        chan *resource[T]
+	idle             IdleSet[T] // 空闲连接集合，按 IdleOrder 取用
+	idleTarget       int64      // 空闲连接目标上限（MaxSize × IdleBufferFactor），超出部分由 shrink 回收
 	inUse            atomic.Int64
 	totalSize        atomic.Int64
-	manager          *closure.Closure[PoolManagerState[T], *PoolManagerActor[T]]
-	waitQueue        *request_queue.LockFreeQueue[*resource[T]]
+	manager          managerMailbox[T]
+	waitQueue        request_queue.WaitQueue[*resource[T]]
 	closeCtx         context.Context
 	cancel           context.CancelFunc
 	config           PoolConfig
 	lastExpandNotify atomic.Int64
 	expanding        atomic.Int64
 	connControl      Conn[T]
+	closed           atomic.Bool  // Close 后置位，所有入口据此快速失败
+	retired          atomic.Bool  // 已从 BalancedPool 移除：Get 按已关闭拒绝，Actor 不再扩容，只等借出的连接归还
+	tryGetMisses     atomic.Int64 // TryGet 未命中次数（累计），供扩容决策感知需求
+	dequeueRate      *rateMeter   // 等待者被服务的速率，用于预估排队时间
+	rejectedDeadline atomic.Int64 // 因预估等待超过截止时间被拒绝的次数
+	shard            int          // 在 ShardedPool 中的下标，创建的资源据此标记归属
+	key              any          // 在 KeyedPool 中对应的 key，Put 据此核对归属
+	affinityHits     atomic.Int64 // GetAffinity 取到了该 key 上次使用的连接
+	affinityMisses   atomic.Int64 // GetAffinity 退化为普通 Get
+	budget           *sizeBudget[T]
+	revokedLeases    atomic.Int64 // 超过持有期限被强制收回的租约数
+	gate             pauseGate    // Pause 期间关闭的闸门
+	draining         atomic.Bool  // Drain 进行中
+	gen              atomic.Int64 // 连接代数，Drain / RecycleAll 各加一，归还时更早代的连接直接关闭
+	recycling        atomic.Bool  // RecycleAll 进行中
+	resources        sync.Map     // 当前存活的全部资源（*resource[T]），供 Snapshot 枚举
+	minSize          atomic.Int64 // 当前 MinSize / MaxSize，Resize 可在运行期调整
+	maxSize          atomic.Int64
+	events           *eventBus       // 最近的生命周期事件及其订阅者
+	waitLatency      latencyRecorder // 获取等待时长，各种获取方式成功时都计入，GetN 按连接数计
+	holdLatency      latencyRecorder // 借出到归还的持有时长
+	getTimeouts      atomic.Int64    // 排队等待中 ctx 结束的获取次数
+	history          statsHistory    // monitorAndAdjust 每个周期的采样
+	trace            tracing
 }
 
-// 定义连接池相关的导出错误
-var (
-	ErrPoolBusy = errors.New("connection pool is busy: maximum capacity reached and wait queue is full")
-)
-
 func NewPool[T any](config PoolConfig, connControl Conn[T]) *Pool[T] {
+	return newPool(config, connControl, poolOptions[T]{})
+}
+
+// poolOptions ShardedPool / KeyedPool 创建子池时使用的内部参数
+type poolOptions[T any] struct {
+	budget    *sizeBudget[T] // 跨池的全局容量预算，扩容时一并检查
+	shard     int            // 在 ShardedPool 中的下标
+	key       any            // 在 KeyedPool 中对应的 key
+	loop      *sharedLoop    // 共享的 Actor 事件循环，nil 时子池自己启动一个
+	noTickers bool           // 心跳和监控由上层统一驱动，子池不启动自己的 ticker
+	register  func(*Pool[T]) // preInit 之前调用，KeyedPool 借此先把子池登记进预算
+}
+
+func newPool[T any](config PoolConfig, connControl Conn[T], opts poolOptions[T]) *Pool[T] {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// ===== 修复点 1：buffer 大小的正确计算 =====
@@ -45,24 +80,59 @@ func NewPool[T any](config PoolConfig, connControl Conn[T]) *Pool[T] {
 	if bufferSize < 1 {
 		bufferSize = 1 // 防止 IdleBufferFactor=0 时创建无缓冲 channel 导致死锁
 	}
+	// 空闲集合本身按 MaxSize 开容量：空闲连接数不会超过 totalSize，归还时就不会因为放不下而被关闭
+	// bufferSize 只作为缩容的参考目标
+	idleCap := int(config.MaxSize)
+	if idleCap < bufferSize {
+		idleCap = bufferSize
+	}
 
 	p := &Pool[T]{
-		resources:        make(chan *resource[T], bufferSize),
-		waitQueue:        request_queue.NewLockFreeQueue[*resource[T]](),
+		idle:             newIdleSet[T](config, idleCap),
+		idleTarget:       int64(bufferSize),
+		waitQueue:        newWaitQueue[T](config),
 		cancel:           cancel,
 		config:           config,
 		lastExpandNotify: atomic.Int64{},
 		expanding:        atomic.Int64{},
 		closeCtx:         ctx,
 		connControl:      connControl,
+		dequeueRate:      newRateMeter(),
+		shard:            opts.shard,
+		key:              opts.key,
+		budget:           opts.budget,
+		events:           newEventBus(),
+		history:          newStatsHistory(),
+		trace:            newTracing(config),
 	}
+	p.minSize.Store(config.MinSize)
+	p.maxSize.Store(config.MaxSize)
 
 	actor := NewPoolManagerActor(config, connControl, &p.totalSize, p.waitQueue, &p.expanding)
-	p.manager = closure.New(actor, closure.WithInboxSize(1000))
-	actor.sharedResources = p.resources
+	if opts.loop != nil {
+		p.manager = newLoopMailbox(opts.loop, actor)
+	} else {
+		p.manager = closure.New(actor, closure.WithInboxSize(1000))
+	}
+	actor.idle = p.idle
+	actor.gate = &p.gate
+	actor.gen = &p.gen
+	actor.resources = &p.resources
+	actor.events = p.events
+	actor.trace = p.trace
+	actor.idleTarget = p.idleTarget
+	actor.budget = opts.budget
+	actor.owner = p
+	actor.tryGetMisses = &p.tryGetMisses
 	actor.manager = p.manager
 
+	if opts.register != nil {
+		opts.register(p)
+	}
 	go p.preInit(config.MinSize, connControl)
+	if opts.noTickers {
+		return p
+	}
 	if config.PingInterval > 0 {
 		go p.pingIdleResources(p.closeCtx)
 	}
@@ -103,14 +173,20 @@ func (p *Pool[T]) monitorAndAdjust(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
-				a.checkAndAdjust(s)
-			})
+		case now := <-ticker.C:
+			p.recordHistory(now)
+			p.adjust()
 		}
 	}
 }
 
+// adjust 异步请求 Actor 做一次扩缩容检查
+func (p *Pool[T]) adjust() {
+	_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		a.checkAndAdjust(s)
+	})
+}
+
 func (p *Pool[T]) doPingRound() {
 	if p.waitQueue.Len() > 0 {
 		return
@@ -123,7 +199,7 @@ func (p *Pool[T]) doPingRound() {
 	p.processPingBatch(batch)
 }
 
-// collectPingBatch 从 resources channel 取最多 maxCount 个连接
+// collectPingBatch 从空闲集合取最多 maxCount 个最冷的连接
 // 中途有等待者进来则放回已取连接并返回 nil
 func (p *Pool[T]) collectPingBatch(maxCount int) []*resource[T] {
 	batch := make([]*resource[T], 0, maxCount)
@@ -135,12 +211,11 @@ func (p *Pool[T]) collectPingBatch(maxCount int) []*resource[T] {
 			}
 			return nil
 		}
-		select {
-		case r := <-p.resources:
-			batch = append(batch, r)
-		default:
-			return batch // channel 已空
+		r, ok := p.idle.PopOldest()
+		if !ok {
+			return batch // 空闲集合已空
 		}
+		batch = append(batch, r)
 	}
 	return batch
 }
@@ -148,13 +223,17 @@ func (p *Pool[T]) collectPingBatch(maxCount int) []*resource[T] {
 // processPingBatch 对一批连接执行 Ping，健康则放回，不健康则驱逐
 func (p *Pool[T]) processPingBatch(batch []*resource[T]) {
 	for _, r := range batch {
-		if err := p.connControl.Ping(r.Conn); err != nil {
+		span := p.trace.start(p.closeCtx, SpanPing, func() []Attr { return []Attr{{Key: AttrResource, Value: r.ID}} })
+		err := p.connControl.Ping(r.Conn)
+		span.End(err)
+		if err != nil {
+			r.setErr(err)
 			// 不健康：异步通知 Actor 关闭并检查是否需要补充
 			_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
-				a.connControl.Close(r.Conn)
-				a.poolTotalSize.Add(-1)
+				a.destroy(r, ReasonPingFailed)
 				a.checkAndAdjust(s)
 			})
+			p.emit(EventPingFailed, r.ID, "", err)
 			if p.config.OnUnhealthy != nil {
 				p.config.OnUnhealthy(err)
 			}
@@ -168,48 +247,53 @@ func (p *Pool[T]) processPingBatch(batch []*resource[T]) {
 	}
 }
 
-// tryReturnOrClose 尝试将资源放回 channel，放回后二次检查是否有等待者
-// channel 满时关闭连接
+// tryReturnOrClose 尝试将资源放回空闲集合，放回后二次检查是否有等待者
+// 空闲集合容量不小于 MaxSize，正常不会放不下；自定义实现拒绝时才关闭连接
 func (p *Pool[T]) tryReturnOrClose(r *resource[T]) {
-	select {
-	case p.resources <- r:
-		// 放回后二次检查：放回瞬间可能有新等待者
-		if p.waitQueue.Len() > 0 {
-			select {
-			case r2 := <-p.resources:
-				if !p.waitQueue.TryDequeue(r2) {
-					select {
-					case p.resources <- r2:
-					default:
-						p.connControl.Close(r2.Conn)
-						p.totalSize.Add(-1)
-					}
-				}
-			default:
+	if !p.idle.Push(r) {
+		p.destroy(r, ReasonOverflow)
+		return
+	}
+	// 放回后二次检查：放回瞬间可能有新等待者
+	if p.waitQueue.Len() > 0 {
+		if r2, ok := p.idle.Pop(); ok && !p.waitQueue.TryDequeue(r2) {
+			if !p.idle.Push(r2) {
+				p.destroy(r2, ReasonOverflow)
 			}
 		}
-	default:
-		p.connControl.Close(r.Conn)
-		p.totalSize.Add(-1)
 	}
 }
 
 func (p *Pool[T]) preInit(count int64, cc Conn[T]) {
 	var failed int64
 	for i := int64(0); i < count; i++ {
-		conn, err := cc.Create()
+		// 与 expand 一样先占位再检查全局预算，预算不够时剩下的交给之后的扩容
+		p.expanding.Add(1)
+		if !p.budget.allow() {
+			p.expanding.Add(-1)
+			log.Printf("[TemplatePoolByGO] preInit: global budget exhausted, %d/%d connections skipped", count-i, count)
+			count = i
+			break
+		}
+		gen := p.gen.Load()
+		span := p.trace.start(p.closeCtx, SpanCreate, func() []Attr { return []Attr{{Key: AttrAttempt, Value: 1}} })
+		conn, labels, err := createLabeled(cc, nil)
+		span.End(err)
 		if err != nil {
+			p.expanding.Add(-1)
 			failed++
-			log.Printf("[TemplatePoolByGO] preInit: failed to create connection %d/%d: %v", i+1, count, err)
+			log.Printf("[TemplatePoolByGO] preInit: failed to create connection %d/%d: %v",
+				i+1, count, p.newError("create", fmt.Sprintf("init-%d", i), ErrCreateFailed, err))
 			continue
 		}
+		// 先加 total 再减 expanding：全局预算求和时只会高估，不会漏算
 		p.totalSize.Add(1)
-		p.resources <- &resource[T]{
-			ID:         fmt.Sprintf("init-%d", i),
-			createTime: time.Now(),
-			updateTime: time.Now(),
-			Conn:       conn,
-		}
+		p.expanding.Add(-1)
+		r := newResource(fmt.Sprintf("init-%d", i), conn, p, gen)
+		r.labels = labels
+		p.track(r)
+		p.emit(EventCreated, r.ID, "", nil)
+		p.handBack(r)
 	}
 	created := count - failed
 	if failed > 0 {
@@ -221,40 +305,185 @@ func (p *Pool[T]) preInit(count int64, cc Conn[T]) {
 }
 
 func (p *Pool[T]) Get(ctx context.Context) (*resource[T], error) {
-	select {
-	case r := <-p.resources:
+	if b, ok := ctx.Value(bindKey{p}).(*binding[T]); ok {
+		if res, ok, err := p.getBound(ctx, b); ok {
+			return res, err
+		}
+	}
+	return p.get(ctx, nil)
+}
+
+// get 入队后除了再看一眼本池空闲集合，还会调用 steal（非空时）从别处取一个已校验的资源
+func (p *Pool[T]) get(ctx context.Context, steal func() (*resource[T], bool)) (res *resource[T], err error) {
+	start := time.Now()
+	defer func() {
+		if err == nil {
+			p.waitLatency.observe(time.Since(start))
+		}
+	}()
+	if p.closed.Load() || p.retired.Load() {
+		return nil, p.newError("get", "", ErrPoolClosed, nil)
+	}
+	if err := p.waitResume(ctx, "get"); err != nil {
+		return nil, err
+	}
+	if r, ok := p.idle.Pop(); ok {
 		return p.validateAndReturn(r)
-	default:
 	}
 	// 前置拒绝，入队前判断
 	if int64(p.waitQueue.Len()) >= p.config.MaxWaitQueue {
+		p.emit(EventRejected, "", "busy", ErrPoolBusy)
 		return nil, ErrPoolBusy
 	}
-	waiter := p.waitQueue.Enqueue()
+	if err := p.admit(ctx, "get", p.waitQueue.Demand()+1); err != nil {
+		return nil, err
+	}
+	waiter := p.enqueue(ctx, 1)
+	// Close / retire 先置位再 Clear 队列：入队后二次检查，避免在 Clear 之后入队的等待者一直挂到 ctx 超时
+	if p.closed.Load() || p.retired.Load() {
+		p.cancelWaiter(waiter)
+		return nil, p.newError("get", "", ErrPoolClosed, nil)
+	}
+
+	if r, ok := p.idle.Pop(); ok {
+		// 取消等待者；若 TryDequeue 已抢先投递，把那个资源取出交还，否则会永久丢失
+		p.cancelWaiter(waiter)
+		return p.validateAndReturn(r)
+	}
+	if steal != nil {
+		if r, ok := steal(); ok {
+			p.cancelWaiter(waiter)
+			return r, nil
+		}
+	}
 
+	p.notifyExpand()
+
+	span := p.trace.start(ctx, SpanGetWait, func() []Attr {
+		return []Attr{{Key: AttrDemand, Value: 1}, {Key: AttrWaiting, Value: p.waitQueue.Len()}}
+	})
 	select {
-	case r := <-p.resources:
-		p.waitQueue.Remove(waiter)
-		// 排空 Enqueue→Remove 竞态窗口内 TryDequeue 投递到 waiter.Ch 的资源
-		// 如果不排空，该资源会永久丢失（goroutine 泄漏 + 连接泄漏）
-		select {
-		case delivered := <-waiter.Ch:
-			// 尝试直接交给下一个等待者，放不回则放回 resources channel
-			if !p.waitQueue.TryDequeue(delivered) {
-				select {
-				case p.resources <- delivered:
-				default:
-					p.connControl.Close(delivered.Conn)
-					p.totalSize.Add(-1)
-				}
-			}
-		default:
+	case <-ctx.Done():
+		span.End(ctx.Err())
+		p.cancelWaiter(waiter)
+		p.getTimeouts.Add(1)
+		p.emit(EventWaiterTimedOut, "", "", ctx.Err())
+		return nil, ctx.Err() // 删掉原来的 ErrPoolBusy 判断
+	case r, ok := <-waiter.Ch:
+		if !ok {
+			err := p.newError("get", "", ErrPoolClosed, nil)
+			span.End(err)
+			return nil, err
 		}
+		span.End(nil)
+		p.dequeueRate.Mark()
 		return p.validateAndReturn(r)
-	default:
 	}
+}
+
+// cancelWaiter 取消普通等待者；Remove 返回 false 说明资源已投递（或正在写入 Ch），
+// 此时阻塞取出并交还给下一个等待者
+func (p *Pool[T]) cancelWaiter(waiter *request_queue.LockFreeWaiter[*resource[T]]) {
+	if p.waitQueue.Remove(waiter) {
+		return
+	}
+	if delivered, ok := <-waiter.Ch; ok {
+		if p.closed.Load() {
+			p.destroy(delivered, ReasonPoolClosed)
+			return
+		}
+		p.handBack(delivered)
+	}
+}
 
-	// 限流的扩容通知
+// TryGet 非阻塞获取：只从空闲集合取，取不到立即返回 false
+// 不入等待队列、不触发扩容，只累加未命中计数，由下一轮 checkAndAdjust 计入需求
+func (p *Pool[T]) TryGet() (*resource[T], bool) {
+	start := time.Now()
+	if p.closed.Load() || p.gate.paused() {
+		return nil, false
+	}
+	r, ok := p.idle.Pop()
+	if !ok {
+		p.tryGetMisses.Add(1)
+		return nil, false
+	}
+	if p.config.ReconnectOnGet {
+		// 不重连、不通知 Actor：关掉失效连接按未命中返回，空出的名额由下一轮 checkAndAdjust 补建
+		if err := p.connControl.Ping(r.Conn); err != nil {
+			r.setErr(err)
+			p.emit(EventPingFailed, r.ID, "", err)
+			p.destroy(r, ReasonPingFailed)
+			p.tryGetMisses.Add(1)
+			return nil, false
+		}
+	}
+	p.inUse.Add(1)
+	p.checkout(r)
+	p.waitLatency.observe(time.Since(start))
+	return r, true
+}
+
+// GetAffinity 优先取最近一次服务 key 的空闲连接（预编译语句、会话状态都在上面），
+// 没有则退化为普通 Get；返回的连接记下 key，归还后可被同 key 的下一次 GetAffinity 命中
+// 需要开启 PoolConfig.Affinity，否则总是退化
+func (p *Pool[T]) GetAffinity(ctx context.Context, key string) (*resource[T], error) {
+	start := time.Now()
+	if p.closed.Load() {
+		return nil, p.newError("get", "", ErrPoolClosed, nil)
+	}
+	if err := p.waitResume(ctx, "get"); err != nil {
+		return nil, err
+	}
+	if keyed, ok := p.idle.(KeyedIdleSet[T]); ok {
+		if r, ok := keyed.PopKey(key); ok {
+			if res, err := p.validateAndReturn(r); err == nil {
+				p.waitLatency.observe(time.Since(start))
+				return p.markAffinity(res, key), nil
+			}
+		}
+	}
+	res, err := p.get(ctx, nil)
+	if err != nil {
+		p.affinityMisses.Add(1)
+		return nil, err
+	}
+	return p.markAffinity(res, key), nil
+}
+
+// markAffinity 按连接上次服务的 key 计命中/未命中（ReconnectOnGet 重建过的连接不算命中），并记下本次的 key
+func (p *Pool[T]) markAffinity(res *resource[T], key string) *resource[T] {
+	if res.affinityKey == key {
+		p.affinityHits.Add(1)
+	} else {
+		p.affinityMisses.Add(1)
+	}
+	res.affinityKey = key
+	return res
+}
+
+// tryPop 只从空闲集合取，不计未命中，ShardedPool 跨分片窃取时使用
+func (p *Pool[T]) tryPop() (*resource[T], bool) {
+	r, ok := p.idle.Pop()
+	if !ok {
+		return nil, false
+	}
+	res, err := p.validateAndReturn(r)
+	if err != nil {
+		return nil, false
+	}
+	return res, true
+}
+
+// GetTimeout 带超时的 Get，等价于 context.WithTimeout + Get
+func (p *Pool[T]) GetTimeout(d time.Duration) (*resource[T], error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return p.Get(ctx)
+}
+
+// notifyExpand 限流的扩容通知（10ms 内最多一次）
+func (p *Pool[T]) notifyExpand() {
 	now := time.Now().UnixMilli()
 	lastNotify := p.lastExpandNotify.Load()
 	if now-lastNotify > 10 {
@@ -264,16 +493,19 @@ func (p *Pool[T]) Get(ctx context.Context) (*resource[T], error) {
 			})
 		}
 	}
+}
 
-	select {
-	case <-ctx.Done():
-		p.waitQueue.Remove(waiter)
-		return nil, ctx.Err() // 删掉原来的 ErrPoolBusy 判断
-	case r, ok := <-waiter.Ch:
-		if !ok {
-			return nil, fmt.Errorf("pool closed")
-		}
-		return p.validateAndReturn(r)
+// handBack 把未交给调用方的空闲资源交还：优先给等待者，其次放回空闲集合，都不行则关闭
+func (p *Pool[T]) handBack(r *resource[T]) {
+	if p.stale(r) {
+		p.destroy(r, ReasonStale)
+		return
+	}
+	if !p.gate.paused() && p.waitQueue.TryDequeue(r) {
+		return
+	}
+	if !p.idle.Push(r) {
+		p.destroy(r, ReasonOverflow)
 	}
 }
 
@@ -281,31 +513,58 @@ func (p *Pool[T]) Put(res *resource[T]) error {
 	if res == nil {
 		return nil
 	}
-	if err := p.connControl.Reset(res.Conn); err != nil {
+	// 通过 Bind 借出的资源只减引用，最后一个引用释放时才走下面的归还
+	if res.bound != nil {
+		return p.unbind(res.bound)
+	}
+	return p.put(res)
+}
+
+func (p *Pool[T]) put(res *resource[T]) error {
+	if p.closed.Load() {
+		// 池子已关闭：直接关闭连接，不再放回
+		p.destroy(res, ReasonPoolClosed)
+		p.inUse.Add(-1)
+		return p.newError("put", res.ID, ErrPoolClosed, nil)
+	}
+	if p.stale(res) {
+		// 换代之前创建的连接：直接关闭，不在 Drain 中时补建一个
+		p.destroy(res, ReasonStale)
+		p.inUse.Add(-1)
+		_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+			if !a.draining {
+				a.expand(s, 1)
+			}
+		})
+		return nil
+	}
+	p.checkin(res)
+	span := p.trace.start(context.Background(), SpanReset, func() []Attr { return []Attr{{Key: AttrResource, Value: res.ID}} })
+	err := p.connControl.Reset(res.Conn)
+	span.End(err)
+	if err != nil {
+		res.setErr(err)
 		_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
-			a.connControl.Close(res.Conn)
-			a.poolTotalSize.Add(-1)
+			a.destroy(res, ReasonResetFailed)
 		})
 		p.inUse.Add(-1)
-		return err
+		return p.newError("put", res.ID, ErrResetFailed, err)
 	}
 
-	if p.waitQueue.TryDequeue(res) {
+	// 暂停期间只放回空闲集合，不交给等待者
+	if !p.gate.paused() && p.waitQueue.TryDequeue(res) {
 		p.inUse.Add(-1)
 		return nil
 	}
 
-	select {
-	case p.resources <- res:
+	if p.idle.Push(res) {
 		p.inUse.Add(-1)
 		return nil
-	default:
 	}
 
 	p.inUse.Add(-1)
 	_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
-		a.connControl.Close(res.Conn)
-		a.poolTotalSize.Add(-1)
+		a.destroy(res, ReasonOverflow)
 	})
 	return nil
 }
@@ -313,49 +572,112 @@ func (p *Pool[T]) Put(res *resource[T]) error {
 func (p *Pool[T]) validateAndReturn(r *resource[T]) (*resource[T], error) {
 	// 如果配置了 Get 时验证连接存活，则 Ping 检测
 	if p.config.ReconnectOnGet {
-		if err := p.connControl.Ping(r.Conn); err != nil {
-			// Ping 失败，尝试重连
-			for retry := 0; retry < p.config.MaxRetries; retry++ {
-				newConn, createErr := p.connControl.Create()
+		if pingErr := p.connControl.Ping(r.Conn); pingErr != nil {
+			r.setErr(pingErr)
+			p.emit(EventPingFailed, r.ID, "", pingErr)
+			// Ping 失败，尝试重连；MaxRetries 为 0 时也至少重连一次
+			maxRetries := p.config.MaxRetries
+			if maxRetries < 1 {
+				maxRetries = 1
+			}
+			var createErr error
+			for retry := 0; retry < maxRetries; retry++ {
+				var newConn T
+				newConn, createErr = p.recreate(r)
 				if createErr == nil {
 					p.connControl.Close(r.Conn)
 					r.Conn = newConn
-					r.createTime = time.Now()
-					r.retryCount++
+					r.createTime.Store(time.Now().UnixNano())
+					r.retryCount.Add(1)
+					r.affinityKey = "" // 新连接上没有旧的会话状态
+					r.gen = p.gen.Load()
+					p.emit(EventReconnected, r.ID, "", pingErr)
 					break
 				}
-				if retry < p.config.MaxRetries-1 {
+				if retry < maxRetries-1 {
 					time.Sleep(p.config.RetryInterval)
 				}
 			}
+			if createErr != nil {
+				// 重连全部失败：不把失效连接交给调用方，关闭并让 Actor 补充
+				_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+					a.destroy(r, ReasonPingFailed)
+					a.checkAndAdjust(s)
+				})
+				return nil, p.newError("get", r.ID, ErrValidationFailed,
+					errors.Join(pingErr, p.newError("create", r.ID, ErrCreateFailed, createErr)))
+			}
 		}
 	}
 	p.inUse.Add(1)
-	r.updateTime = time.Now()
+	p.checkout(r)
 	return r, nil
 }
 
+// Close 关闭池子，重复调用是安全的
 func (p *Pool[T]) Close() {
+	if !p.closed.CompareAndSwap(false, true) {
+		return
+	}
 	p.cancel()
+	// 唤醒暂停期间排队的调用方，它们随后看到 closed
+	if ch := p.gate.ch.Swap(nil); ch != nil {
+		close(*ch)
+	}
 	p.waitQueue.Clear()
 	p.manager.StopAndWait()
+	p.closeIdle()
+	p.events.close()
+}
+
+// closeIdle 关闭当前所有空闲连接，返回关闭的数量
+func (p *Pool[T]) closeIdle() int {
+	n := 0
 	for {
-		select {
-		case r := <-p.resources:
-			p.connControl.Close(r.Conn)
-		default:
-			return
+		r, ok := p.idle.Pop()
+		if !ok {
+			return n
 		}
+		p.destroy(r, ReasonPoolClosed)
+		n++
 	}
 }
 
+// IsClosed 返回池子是否已关闭，供健康检查使用
+func (p *Pool[T]) IsClosed() bool {
+	return p.closed.Load()
+}
+
 func (p *Pool[T]) Stats(ctx context.Context) (map[string]int64, error) {
+	if p.closed.Load() {
+		return nil, p.newError("stats", "", ErrPoolClosed, nil)
+	}
 	return map[string]int64{
-		"total_size":     p.totalSize.Load(),
-		"pool_available": int64(len(p.resources)),
-		"pool_in_use":    p.inUse.Load(),
-		"waiting_count":  int64(p.waitQueue.Len()),
-		"expanding":      p.expanding.Load(),
-		"buffer_cap":     int64(cap(p.resources)), // 新增：显示 buffer 容量
+		"total_size":        p.totalSize.Load(),
+		"pool_available":    int64(p.idle.Len()),
+		"pool_in_use":       p.inUse.Load(),
+		"waiting_count":     int64(p.waitQueue.Len()),
+		"waiting_demand":    p.waitQueue.Demand(),
+		"expanding":         p.expanding.Load(),
+		"buffer_cap":        p.idleTarget,        // 空闲连接目标上限（MaxSize × IdleBufferFactor）
+		"idle_cap":          int64(p.idle.Cap()), // 空闲集合实际容量
+		"try_get_miss":      p.tryGetMisses.Load(),
+		"rejected_deadline": p.rejectedDeadline.Load(),
+		"affinity_hits":     p.affinityHits.Load(),
+		"affinity_misses":   p.affinityMisses.Load(),
+		"revoked_leases":    p.revokedLeases.Load(),
+		"paused":            boolToInt64(p.gate.paused()),
+		"draining":          boolToInt64(p.draining.Load()),
+		"get_timeouts":      p.getTimeouts.Load(),
+		"events_dropped":    p.events.dropped.Load(),
+		"min_size":          p.minSize.Load(),
+		"max_size":          p.maxSize.Load(),
 	}, nil
 }
+
+func boolToInt64(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
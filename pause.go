@@ -0,0 +1,133 @@
+package pool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// PauseMode Pause 期间新的获取请求如何处理
+type PauseMode int
+
+const (
+	// PauseQueue 阻塞等待 Resume（或 ctx 结束）
+	PauseQueue PauseMode = iota
+	// PauseFail 立即返回 ErrPoolPaused
+	PauseFail
+)
+
+// pauseGate 暂停闸门：暂停期间持有一个 channel，Resume 时关闭它唤醒排队的调用方
+type pauseGate struct {
+	ch atomic.Pointer[chan struct{}]
+}
+
+func (g *pauseGate) paused() bool { return g.ch.Load() != nil }
+
+// Pause 暂停分配连接但不关闭池：新的获取请求按 PauseMode 排队或失败，
+// 归还的连接只放回空闲集合，不再交给等待者，Actor 也不再扩缩容。重复调用是安全的
+func (p *Pool[T]) Pause() {
+	if p.closed.Load() {
+		return
+	}
+	ch := make(chan struct{})
+	if p.gate.ch.CompareAndSwap(nil, &ch) {
+		p.emit(EventPaused, "", "", nil)
+	}
+}
+
+// Resume 恢复分配，唤醒暂停期间排队的调用方，并把空闲连接交给等待者
+func (p *Pool[T]) Resume() {
+	ch := p.gate.ch.Swap(nil)
+	if ch == nil {
+		return
+	}
+	close(*ch)
+	p.emit(EventResumed, "", "", nil)
+	p.pumpIdle()
+	p.adjust()
+}
+
+// IsPaused 返回池子是否处于暂停状态
+func (p *Pool[T]) IsPaused() bool { return p.gate.paused() }
+
+// waitResume 暂停时按 PauseMode 排队或失败，未暂停时直接返回
+func (p *Pool[T]) waitResume(ctx context.Context, op string) error {
+	ch := p.gate.ch.Load()
+	if ch == nil {
+		return nil
+	}
+	if p.config.PauseMode == PauseFail {
+		p.emit(EventRejected, "", "paused", ErrPoolPaused)
+		return p.newError(op, "", ErrPoolPaused, nil)
+	}
+	select {
+	case <-*ch:
+		if p.closed.Load() {
+			return p.newError(op, "", ErrPoolClosed, nil)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain 换掉池里的所有连接，例如数据库主从切换之后：
+// 关闭全部空闲连接，借出中的连接归还时直接关闭，等它们全部归还（或 ctx 结束）后重新建 MinSize 个新连接
+// 期间 Actor 暂停扩缩容。不需要先 Pause：排空期间的 Get 没有可用连接，只能排队（或等到 ctx 结束），
+// 之后重建的连接优先交给它们，不会拿到旧连接；配合 Pause 则是让新请求在 Resume 前都不进来
+func (p *Pool[T]) Drain(ctx context.Context) error {
+	if p.closed.Load() {
+		return p.newError("drain", "", ErrPoolClosed, nil)
+	}
+	if !p.draining.CompareAndSwap(false, true) {
+		return p.newError("drain", "", ErrDrainInProgress, nil)
+	}
+	defer p.draining.Store(false)
+
+	// 先置位再换代：之后归还的旧连接都按过期处理
+	if err := p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		a.draining = true
+		a.gen.Add(1)
+		a.closeStale()
+	}); err != nil {
+		return p.newError("drain", "", ErrActorStopped, err)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	var err error
+wait:
+	for p.inUse.Load() > 0 || p.expanding.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break wait
+		case <-ticker.C:
+			// 心跳、取消的等待者可能把旧连接放回空闲集合，每轮再清一次
+			_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+				a.closeStale()
+			})
+		}
+	}
+
+	_ = p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		a.draining = false
+		a.closeStale()
+		if need := s.config.MinSize - a.poolTotalSize.Load() - a.expanding.Load(); need > 0 {
+			a.expand(s, need)
+		}
+	})
+	p.emit(EventDrained, "", "", err)
+	return err
+}
+
+// closeStale 关闭空闲集合中换代之前创建的连接
+func (a *PoolManagerActor[T]) closeStale() {
+	gen := a.gen.Load()
+	for _, r := range a.owner.removeIdle(func(r *resource[T]) bool { return r.gen < gen }) {
+		a.destroy(r, ReasonStale)
+	}
+}
+
+// stale 资源是否在最近一次 Drain / RecycleAll 换代之前创建
+func (p *Pool[T]) stale(r *resource[T]) bool { return r.gen < p.gen.Load() }
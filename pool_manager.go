@@ -1,7 +1,9 @@
 package pool
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -16,21 +18,76 @@ type PoolManagerState[T any] struct {
 
 type PoolManagerActor[T any] struct {
 	closure.BaseActor[PoolManagerState[T]]
-	config          PoolConfig
-	connControl     Conn[T]
-	manager         *closure.Closure[PoolManagerState[T], *PoolManagerActor[T]]
-	sharedResources chan *resource[T]
-	waitQueue       *request_queue.LockFreeQueue[*resource[T]]
-	poolTotalSize   *atomic.Int64
-	expanding       *atomic.Int64 // 新增：记录扩容中的连接数
-	initialized     atomic.Bool
+	config        PoolConfig
+	connControl   Conn[T]
+	manager       managerMailbox[T]
+	idle          IdleSet[T] // 与 Pool 共享的空闲集合
+	idleTarget    int64      // 空闲连接目标上限，缓冲利用率以它为分母
+	waitQueue     request_queue.WaitQueue[*resource[T]]
+	poolTotalSize *atomic.Int64
+	expanding     *atomic.Int64 // 新增：记录扩容中的连接数
+	initialized   atomic.Bool
+	tryGetMisses  *atomic.Int64  // Pool.TryGet 未命中累计计数
+	lastMisses    int64          // 上一轮 checkAndAdjust 读到的未命中计数，仅 Actor 内访问
+	budget        *sizeBudget[T] // 跨池的全局容量预算，独立池为 nil
+	owner         *Pool[T]       // 所属的池，新建的资源据此标记归属
+	gate          *pauseGate     // 与 Pool 共享的暂停闸门
+	gen           *atomic.Int64  // 与 Pool 共享的连接代数
+	draining      bool           // Drain 进行中，暂停扩缩容，仅 Actor 内访问
+	resources     *sync.Map      // 与 Pool 共享的资源登记表
+	events        *eventBus      // 与 Pool 共享的事件分发
+	trace         tracing
+}
+
+// managerMailbox 向 Actor 投递消息的入口
+// 独立的 Pool 直接用自己的 closure；KeyedPool 的子池共用一个事件循环（loopMailbox）
+type managerMailbox[T any] interface {
+	Send(fn func(*PoolManagerActor[T], *PoolManagerState[T])) error
+	StopAndWait()
+}
+
+// sharedLoop 多个子池共用的事件循环，只负责串行执行投递进来的闭包
+type sharedLoop = closure.Closure[struct{}, closure.BaseActor[struct{}]]
+
+func newSharedLoop() *sharedLoop {
+	return closure.New[struct{}](closure.BaseActor[struct{}]{}, closure.WithInboxSize(1000))
+}
+
+// loopMailbox 把一个子池的 Actor 挂到共享事件循环上：消息在共享协程里执行，状态仍归子池所有
+type loopMailbox[T any] struct {
+	loop    *sharedLoop
+	actor   *PoolManagerActor[T]
+	state   PoolManagerState[T]
+	stopped atomic.Bool
+}
+
+func newLoopMailbox[T any](loop *sharedLoop, actor *PoolManagerActor[T]) *loopMailbox[T] {
+	return &loopMailbox[T]{loop: loop, actor: actor, state: actor.Init()}
+}
+
+func (m *loopMailbox[T]) Send(fn func(*PoolManagerActor[T], *PoolManagerState[T])) error {
+	if m.stopped.Load() {
+		return closure.ErrActorStopped
+	}
+	return m.loop.Send(func(closure.BaseActor[struct{}], *struct{}) {
+		if !m.stopped.Load() {
+			fn(m.actor, &m.state)
+		}
+	})
+}
+
+// StopAndWait 之后投递的和还在排队的消息都被丢弃；用一次同步调用等正在执行的消息结束
+// 不能在共享事件循环内调用
+func (m *loopMailbox[T]) StopAndWait() {
+	m.stopped.Store(true)
+	_, _ = m.loop.Call(func(closure.BaseActor[struct{}], *struct{}) any { return nil })
 }
 
 func NewPoolManagerActor[T any](
 	config PoolConfig,
 	connControl Conn[T],
 	totalSize *atomic.Int64,
-	wq *request_queue.LockFreeQueue[*resource[T]],
+	wq request_queue.WaitQueue[*resource[T]],
 	expanding *atomic.Int64,
 ) *PoolManagerActor[T] {
 	return &PoolManagerActor[T]{
@@ -52,13 +109,24 @@ func (a *PoolManagerActor[T]) Init() PoolManagerState[T] {
 // checkAndAdjust 检测并调整池大小（更敏感的扩容触发条件）
 // ===== 核心修复：扩容逻辑不应该依赖 buffer 的 idleRatio =====
 func (a *PoolManagerActor[T]) checkAndAdjust(s *PoolManagerState[T]) {
-	if !a.initialized.Load() {
+	if !a.initialized.Load() || a.draining || a.gate.paused() || a.owner.retired.Load() {
 		return
 	}
 
-	poolLen := int64(len(a.sharedResources))
-	capacity := int64(cap(a.sharedResources))
-	waiting := int64(a.waitQueue.Len())
+	poolLen := int64(a.idle.Len())
+	capacity := a.idleTarget
+	if capacity < 1 {
+		capacity = int64(a.idle.Cap())
+	}
+	// 先回收已过截止时间的等待者，再用 Demand 而不是 Len：一个 GetN 批量等待者代表 n 个需求
+	a.waitQueue.Reap()
+	waiting := a.waitQueue.Demand()
+	// TryGet 未命中不入队，但同样代表需求：按本轮新增的未命中数计入等待量
+	if a.tryGetMisses != nil {
+		misses := a.tryGetMisses.Load()
+		waiting += misses - a.lastMisses
+		a.lastMisses = misses
+	}
 	currentTotal := a.poolTotalSize.Load()
 	expandingCount := a.expanding.Load()
 	effectiveTotal := currentTotal + expandingCount
@@ -166,29 +234,35 @@ func (a *PoolManagerActor[T]) calculateExpandSize(s *PoolManagerState[T], waitin
 }
 
 func (a *PoolManagerActor[T]) expand(s *PoolManagerState[T], expandSize int64) {
-	if a.poolTotalSize.Load()+a.expanding.Load() >= s.config.MaxSize {
+	if a.poolTotalSize.Load()+a.expanding.Load() >= s.config.MaxSize || a.budget.exhausted() {
 		return
 	}
 
+	started := int64(0)
 	for i := int64(0); i < expandSize; i++ {
 		newExpanding := a.expanding.Add(1)
 		newTotal := a.poolTotalSize.Load() + newExpanding
 
-		if newTotal > s.config.MaxSize {
+		// 先占位再检查全局预算：并发占位时最多一起退回，不会一起超额
+		if newTotal > s.config.MaxSize || !a.budget.allow() {
 			a.expanding.Add(-1)
 			break
 		}
+		started++
 
 		go func(idx int64) {
-
+			gen := a.gen.Load()
 			var conn T
+			var labels Labels
 			var err error
 			maxRetries := s.config.MaxRetries
 			if maxRetries < 1 {
 				maxRetries = 1
 			}
 			for retry := 0; retry < maxRetries; retry++ {
-				conn, err = a.connControl.Create()
+				span := a.trace.start(context.Background(), SpanCreate, func() []Attr { return []Attr{{Key: AttrAttempt, Value: retry + 1}} })
+				conn, labels, err = createLabeled(a.connControl, nil)
+				span.End(err)
 				if err == nil {
 					break
 				}
@@ -202,26 +276,35 @@ func (a *PoolManagerActor[T]) expand(s *PoolManagerState[T], expandSize int64) {
 			}
 
 			_ = a.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
-				a.expanding.Add(-1)
-				a.poolTotalSize.Add(1)
-				res := &resource[T]{
-					ID:         fmt.Sprintf("exp-%d-%d", time.Now().UnixNano(), idx),
-					createTime: time.Now(),
-					updateTime: time.Now(),
-					Conn:       conn,
+				// 创建期间被 Drain / RecycleAll 换代：连的可能还是旧主库、旧凭据，直接丢弃
+				// Drain 结束时会统一重建；RecycleAll 不会，立即补建一个新代的，否则等它的请求只能挂到超时
+				if gen < a.gen.Load() {
+					a.connControl.Close(conn)
+					a.expanding.Add(-1)
+					if !a.draining {
+						a.expand(s, 1)
+					}
+					return
 				}
-				if a.waitQueue.TryDequeue(res) {
+				// 先加 total 再减 expanding：全局预算求和时只会高估，不会漏算
+				a.poolTotalSize.Add(1)
+				a.expanding.Add(-1)
+				res := newResource(fmt.Sprintf("exp-%d-%d", time.Now().UnixNano(), idx), conn, a.owner, gen)
+				res.labels = labels
+				a.resources.Store(res, struct{}{})
+				a.emit(EventCreated, res.ID, "", nil)
+				if !a.gate.paused() && a.waitQueue.TryDequeue(res) {
 					return
 				}
-				select {
-				case a.sharedResources <- res:
-				default:
-					a.connControl.Close(conn)
-					a.poolTotalSize.Add(-1)
+				if !a.idle.Push(res) {
+					a.destroy(res, ReasonOverflow)
 				}
 			})
 		}(i)
 	}
+	if started > 0 {
+		a.events.publish(Event{Kind: EventExpanded, N: started})
+	}
 }
 
 // shrink 缩容逻辑（优先驱逐超龄连接）
@@ -241,57 +324,40 @@ func (a *PoolManagerActor[T]) shrink(s *PoolManagerState[T]) {
 		shrinkSize = target
 	}
 
-	// 两阶段缩容：先收集连接，优先关闭超龄的
-	// 第一阶段：收集一批连接
-	type candidate struct {
-		r       *resource[T]
-		expired bool
-	}
-	candidates := make([]candidate, 0, shrinkSize)
-	collected := int64(0)
-	for collected < shrinkSize {
-		select {
-		case r := <-a.sharedResources:
-			expired := s.config.SurviveTime > 0 && time.Since(r.createTime) > s.config.SurviveTime
-			candidates = append(candidates, candidate{r: r, expired: expired})
-			collected++
-		default:
-			goto process
-		}
-	}
-
-process:
-	if len(candidates) == 0 {
-		return
-	}
-
-	// 第二阶段：优先关闭超龄连接，不足再关正常连接，剩余的放回
-	survivors := make([]*resource[T], 0)
+	// 第一阶段：就地摘除旧代（RecycleAll 换代前创建）和超龄连接，不必把空闲集合整个倒出来
 	closedCount := int64(0)
-	for _, c := range candidates {
-		if c.expired {
-			a.connControl.Close(c.r.Conn)
-			a.poolTotalSize.Add(-1)
-			closedCount++
+	now := time.Now()
+	gen := a.gen.Load()
+	matched := int64(0)
+	expired := a.owner.removeIdle(func(r *resource[T]) bool {
+		if matched >= shrinkSize {
+			return false
+		}
+		if r.gen >= gen && (s.config.SurviveTime <= 0 || now.Sub(r.CreatedAt()) <= s.config.SurviveTime) {
+			return false
+		}
+		matched++
+		return true
+	})
+	for _, r := range expired {
+		if r.gen < gen {
+			a.destroy(r, ReasonStale)
 		} else {
-			survivors = append(survivors, c.r)
+			a.destroy(r, ReasonExpired)
 		}
+		closedCount++
 	}
 
-	// 如果超龄连接不够缩容目标，从正常连接中继续关闭
-	for _, r := range survivors {
-		if closedCount >= shrinkSize {
-			// 已达目标，剩余放回
-			select {
-			case a.sharedResources <- r:
-			default:
-				a.connControl.Close(r.Conn)
-				a.poolTotalSize.Add(-1)
-			}
-		} else {
-			a.connControl.Close(r.Conn)
-			a.poolTotalSize.Add(-1)
-			closedCount++
+	// 第二阶段：超龄连接不够缩容目标，从冷端继续关闭（LIFO 模式下就是最久没用过的）
+	for closedCount < shrinkSize {
+		r, ok := a.idle.PopOldest()
+		if !ok {
+			break
 		}
+		a.destroy(r, ReasonShrink)
+		closedCount++
+	}
+	if closedCount > 0 {
+		a.events.publish(Event{Kind: EventShrunk, N: closedCount})
 	}
 }
@@ -109,6 +109,24 @@ func (fc *FakeConnControl) Create() (*FakeConn, error) {
 	return conn, nil
 }
 
+// testConfig 功能测试共用的配置：空闲集合放得下全部连接，不开心跳和监控
+func testConfig(minSize, maxSize int64) PoolConfig {
+	return PoolConfig{
+		MinSize:          minSize,
+		MaxSize:          maxSize,
+		IdleBufferFactor: 1.0,
+		MaxWaitQueue:     1000,
+	}
+}
+
+// startTestPool 测试结束时关闭 p，并等 preInit 建好 MinSize 个连接
+func startTestPool[P interface{ Close() }](t testing.TB, p P) P {
+	t.Helper()
+	t.Cleanup(p.Close)
+	time.Sleep(200 * time.Millisecond)
+	return p
+}
+
 // BenchmarkStress_GetPut_RealUse 带真实使用时间的压测
 func BenchmarkStress_GetPut_RealUse(b *testing.B) {
 	logFile, err := os.OpenFile("benchmark_optimized.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
@@ -1060,3 +1078,442 @@ func TestGetResourceLeakWindow(t *testing.T) {
 
 	_ = leakedResources.Load()
 }
+
+// TestTryGet 验证 TryGet 非阻塞：有空闲连接时命中，空了立即返回 false 并计入未命中
+func TestTryGet(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 2), &FakeConnControl{}))
+
+	held := make([]*Resource[*FakeConn], 0, 2)
+	for i := 0; i < 2; i++ {
+		res, ok := p.TryGet()
+		if !ok {
+			t.Fatalf("TryGet #%d should hit an idle connection", i)
+		}
+		held = append(held, res)
+	}
+
+	start := time.Now()
+	if _, ok := p.TryGet(); ok {
+		t.Fatal("TryGet should miss when no idle connection is left")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("TryGet should not block, took %v", elapsed)
+	}
+
+	stats, _ := p.Stats(context.Background())
+	if stats["try_get_miss"] != 1 {
+		t.Errorf("expected try_get_miss=1, got %d", stats["try_get_miss"])
+	}
+	if stats["waiting_count"] != 0 {
+		t.Errorf("TryGet must not enqueue, waiting_count=%d", stats["waiting_count"])
+	}
+
+	for _, res := range held {
+		p.Put(res)
+	}
+}
+
+// TestTryGet_ReconnectOnGet Ping 失败时 TryGet 不重连也不触发扩容，关掉失效连接按未命中返回
+func TestTryGet_ReconnectOnGet(t *testing.T) {
+	ctrl := &FakeConnControl{}
+	config := testConfig(2, 2)
+	config.ReconnectOnGet = true
+	p := startTestPool(t, NewPool(config, ctrl))
+
+	res, ok := p.TryGet()
+	if !ok {
+		t.Fatal("TryGet should hit an idle connection")
+	}
+	dead := res.Conn
+	dead.pingErr = errors.New("connection lost")
+	p.Put(res)
+
+	ctrl.createDelay = time.Second // 一旦重连或扩容就会明显变慢
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if res, ok := p.TryGet(); ok {
+			if res.Conn == dead {
+				t.Fatal("TryGet handed out a connection that failed Ping")
+			}
+			p.Put(res)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("TryGet blocked for %v", elapsed)
+	}
+	if !dead.closed {
+		t.Error("connection that failed Ping should be closed")
+	}
+	stats, _ := p.Stats(context.Background())
+	if stats["total_size"] != 1 || stats["expanding"] != 0 || stats["try_get_miss"] != 1 {
+		t.Errorf("total=%d expanding=%d miss=%d, want 1, 0 and 1",
+			stats["total_size"], stats["expanding"], stats["try_get_miss"])
+	}
+}
+
+// TestGetTimeout 验证 GetTimeout 在池子耗尽时按超时返回
+func TestGetTimeout(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 1), &FakeConnControl{}))
+
+	res, err := p.GetTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("GetTimeout failed: %v", err)
+	}
+	defer p.Put(res)
+
+	start := time.Now()
+	if _, err := p.GetTimeout(50 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetTimeout took too long: %v", elapsed)
+	}
+}
+
+// TestGetPriority 验证优先级队列下高优先级等待者先拿到归还的连接
+func TestGetPriority(t *testing.T) {
+	config := testConfig(1, 1)
+	config.WaitQueueMode = WaitQueuePriority
+	config.PriorityAging = time.Hour // 测试期间不发生老化
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	held, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	order := make(chan int, 4)
+	var wg sync.WaitGroup
+	start := func(prio int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			res, err := p.GetPriority(ctx, prio)
+			if err != nil {
+				t.Errorf("GetPriority(%d) failed: %v", prio, err)
+				return
+			}
+			order <- prio
+			time.Sleep(10 * time.Millisecond)
+			p.Put(res)
+		}()
+	}
+	// 低优先级先排队，高优先级后到
+	start(0)
+	start(0)
+	time.Sleep(50 * time.Millisecond)
+	start(9)
+	time.Sleep(50 * time.Millisecond)
+
+	p.Put(held)
+	wg.Wait()
+	close(order)
+
+	if first := <-order; first != 9 {
+		t.Errorf("expected high-priority waiter to be served first, got priority %d", first)
+	}
+}
+
+// TestWaitingCountExcludesTimedOut 验证超时退出的等待者不再计入 waiting_count
+func TestWaitingCountExcludesTimedOut(t *testing.T) {
+	config := testConfig(1, 1)
+	config.MaxWaitQueue = 5
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	held, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// 填满等待队列后全部超时
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.GetTimeout(20 * time.Millisecond)
+		}()
+	}
+	wg.Wait()
+
+	stats, _ := p.Stats(context.Background())
+	if stats["waiting_count"] != 0 || stats["waiting_demand"] != 0 {
+		t.Errorf("timed-out waiters should not count, waiting_count=%d waiting_demand=%d",
+			stats["waiting_count"], stats["waiting_demand"])
+	}
+
+	// 队列没有被已超时的节点占满，新的等待者可以正常入队并拿到归还的连接
+	done := make(chan error, 1)
+	go func() {
+		res, err := p.GetTimeout(time.Second)
+		if err == nil {
+			p.Put(res)
+		}
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	p.Put(held)
+	if err := <-done; err != nil {
+		t.Fatalf("Get after timeouts failed: %v", err)
+	}
+}
+
+// TestAdmissionControl 验证预估等待超过 ctx 截止时间时立即返回 ErrWouldExceedDeadline
+func TestAdmissionControl(t *testing.T) {
+	config := testConfig(1, 1)
+	config.AdmissionControl = true
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	held, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	// 建立吞吐历史：每 30ms 服务一个等待者（约 33 次/秒）
+	for i := 0; i < 8; i++ {
+		r := held
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			p.Put(r)
+		}()
+		if held, err = p.GetTimeout(time.Second); err != nil {
+			t.Fatalf("queued Get failed: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Millisecond)
+	defer cancel()
+	_, err = p.Get(ctx)
+	if !errors.Is(err, ErrWouldExceedDeadline) {
+		t.Fatalf("expected ErrWouldExceedDeadline, got %v", err)
+	}
+	stats, _ := p.Stats(context.Background())
+	if stats["rejected_deadline"] != 1 {
+		t.Errorf("expected rejected_deadline=1, got %d", stats["rejected_deadline"])
+	}
+
+	// 截止时间充足的调用方照常排队
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		p.Put(held)
+	}()
+	res, err := p.GetTimeout(2 * time.Second)
+	if err != nil {
+		t.Fatalf("Get with enough budget failed: %v", err)
+	}
+	p.Put(res)
+}
+
+// TestIdleOrder 验证 LIFO 模式复用最近归还的连接，FIFO 模式轮转所有空闲连接
+func TestIdleOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		order    IdleOrder
+		wantSame bool
+	}{
+		{"LIFO", IdleLIFO, true},
+		{"FIFO", IdleFIFO, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := testConfig(3, 3)
+			config.IdleOrder = tc.order
+			p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+			first, err := p.Get(context.Background())
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			p.Put(first)
+			for i := 0; i < 3; i++ {
+				res, err := p.Get(context.Background())
+				if err != nil {
+					t.Fatalf("Get failed: %v", err)
+				}
+				if same := res.Conn == first.Conn; same != tc.wantSame {
+					t.Errorf("round %d: reused hot connection=%v, want %v", i, same, tc.wantSame)
+				}
+				p.Put(res)
+				if !tc.wantSame {
+					break // FIFO 只检查下一次拿到的不是刚归还的那个
+				}
+			}
+		})
+	}
+}
+
+// TestGetAffinity 同 key 再次取用时拿回上次的连接，未命中时退化为任意空闲连接
+func TestGetAffinity(t *testing.T) {
+	config := testConfig(3, 3)
+	config.Affinity = true
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	ctx := context.Background()
+
+	a, err := p.GetAffinity(ctx, "tenant-a")
+	if err != nil {
+		t.Fatalf("GetAffinity failed: %v", err)
+	}
+	b, err := p.GetAffinity(ctx, "tenant-b")
+	if err != nil {
+		t.Fatalf("GetAffinity failed: %v", err)
+	}
+	if a.AffinityKey() != "tenant-a" {
+		t.Errorf("AffinityKey=%q, want tenant-a", a.AffinityKey())
+	}
+	p.Put(a)
+	p.Put(b)
+
+	// FIFO 下普通 Pop 会先拿到最早放回的那个，亲和取用必须跳过它
+	for i := 0; i < 3; i++ {
+		res, err := p.GetAffinity(ctx, "tenant-b")
+		if err != nil {
+			t.Fatalf("GetAffinity failed: %v", err)
+		}
+		if res.Conn != b.Conn {
+			t.Errorf("round %d: tenant-b got a different connection", i)
+		}
+		p.Put(res)
+	}
+
+	// 没有服务过的 key 退化为任意空闲连接
+	res, err := p.GetAffinity(ctx, "tenant-c")
+	if err != nil {
+		t.Fatalf("GetAffinity fallback failed: %v", err)
+	}
+	p.Put(res)
+
+	stats, _ := p.Stats(ctx)
+	if stats["affinity_hits"] != 3 || stats["affinity_misses"] != 3 {
+		t.Errorf("hits=%d misses=%d, want 3 and 3", stats["affinity_hits"], stats["affinity_misses"])
+	}
+}
+
+// TestPutKeepsConnsBeyondIdleBuffer 验证空闲连接数超过 IdleBufferFactor 目标时，归还的连接不会被立即关闭
+func TestPutKeepsConnsBeyondIdleBuffer(t *testing.T) {
+	for _, shards := range []int{0, 4} {
+		t.Run(fmt.Sprintf("shards=%d", shards), func(t *testing.T) {
+			config := testConfig(10, 10)
+			config.IdleBufferFactor = 0.2 // 目标只留 2 个空闲
+			config.IdleShards = shards
+			p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+			ctx := context.Background()
+			held := make([]*Resource[*FakeConn], 0, 10)
+			for i := 0; i < 10; i++ {
+				res, err := p.Get(ctx)
+				if err != nil {
+					t.Fatalf("Get %d failed: %v", i, err)
+				}
+				held = append(held, res)
+			}
+			for _, res := range held {
+				if err := p.Put(res); err != nil {
+					t.Fatalf("Put failed: %v", err)
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			stats, _ := p.Stats(ctx)
+			if stats["total_size"] != 10 || stats["pool_available"] != 10 {
+				t.Errorf("expected all 10 connections kept idle, got total=%d available=%d",
+					stats["total_size"], stats["pool_available"])
+			}
+			for _, res := range held {
+				if res.Conn.closed {
+					t.Fatalf("connection %d closed on Put", res.Conn.id)
+				}
+			}
+		})
+	}
+}
+
+// TestIdleSetRemoveIf 验证两种空闲集合的遍历摘除与取用顺序
+func TestIdleSetRemoveIf(t *testing.T) {
+	for name, set := range map[string]IdleSet[*FakeConn]{
+		"chan":    NewChanIdleSet[*FakeConn](8),
+		"sharded": NewShardedIdleSet[*FakeConn](8, 1, IdleFIFO),
+		"keyed":   NewKeyedIdleSet[*FakeConn](8, IdleFIFO),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 6; i++ {
+				if !set.Push(&Resource[*FakeConn]{ID: fmt.Sprint(i), Conn: NewFakeConn()}) {
+					t.Fatalf("Push %d failed", i)
+				}
+			}
+			removed := set.RemoveIf(func(r *Resource[*FakeConn]) bool {
+				return r.ID == "1" || r.ID == "4"
+			})
+			if len(removed) != 2 || set.Len() != 4 {
+				t.Fatalf("removed=%d len=%d, want 2 and 4", len(removed), set.Len())
+			}
+			var order []string
+			for {
+				r, ok := set.PopOldest()
+				if !ok {
+					break
+				}
+				order = append(order, r.ID)
+			}
+			if got := fmt.Sprint(order); got != "[0 2 3 5]" {
+				t.Errorf("remaining order %s, want [0 2 3 5]", got)
+			}
+		})
+	}
+}
+
+// BenchmarkIdleSet 对比 channel 与分片互斥锁两种空闲集合在并发 Push/Pop 下的开销
+func BenchmarkIdleSet(b *testing.B) {
+	const capacity = 1024
+	impls := []struct {
+		name string
+		new  func() IdleSet[*FakeConn]
+	}{
+		{"chan", func() IdleSet[*FakeConn] { return NewChanIdleSet[*FakeConn](capacity) }},
+		{"sharded-1", func() IdleSet[*FakeConn] { return NewShardedIdleSet[*FakeConn](capacity, 1, IdleFIFO) }},
+		{"sharded-8", func() IdleSet[*FakeConn] { return NewShardedIdleSet[*FakeConn](capacity, 8, IdleFIFO) }},
+		{"sharded-8-lifo", func() IdleSet[*FakeConn] { return NewShardedIdleSet[*FakeConn](capacity, 8, IdleLIFO) }},
+		{"keyed", func() IdleSet[*FakeConn] { return NewKeyedIdleSet[*FakeConn](capacity, IdleFIFO) }},
+	}
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			set := impl.new()
+			for i := 0; i < capacity/2; i++ {
+				set.Push(&Resource[*FakeConn]{Conn: NewFakeConn()})
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if r, ok := set.Pop(); ok {
+						set.Push(r)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkPool_GetPut_IdleSet 在整池 Get/Put 路径上对比两种空闲集合
+func BenchmarkPool_GetPut_IdleSet(b *testing.B) {
+	for _, shards := range []int{0, 8} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			config := testConfig(50, 100)
+			config.IdleShards = shards
+			config.MaxRetries = 3
+			config.RetryInterval = 100 * time.Millisecond
+			config.MaxWaitQueue = 10000
+			p := startTestPool(b, NewPool(config, &FakeConnControl{}))
+			ctx := context.Background()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					res, err := p.Get(ctx)
+					if err != nil {
+						b.Fatal(err)
+					}
+					_ = p.Put(res)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// switchConn 可以整体切换为故障状态的后端
+type switchConn struct {
+	FakeConnControl
+	down atomic.Bool
+}
+
+func (s *switchConn) Create() (*FakeConn, error) {
+	if s.down.Load() {
+		return nil, errors.New("endpoint unreachable")
+	}
+	return s.FakeConnControl.Create()
+}
+
+func (s *switchConn) Ping(c *FakeConn) error {
+	if s.down.Load() {
+		return errors.New("endpoint unreachable")
+	}
+	return s.FakeConnControl.Ping(c)
+}
+
+func TestBalancedPool_RoundRobin(t *testing.T) {
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewBalancedPool(config, []Endpoint[*FakeConn]{
+		{Name: "a", Conn: &FakeConnControl{}},
+		{Name: "b", Conn: &FakeConnControl{}},
+		{Name: "c", Conn: &FakeConnControl{}},
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var held []*Resource[*FakeConn]
+	for i := 0; i < 3; i++ {
+		res, err := p.Get(ctx)
+		if err != nil {
+			t.Fatalf("Get %d failed: %v", i, err)
+		}
+		held = append(held, res)
+	}
+	per, _ := p.BackendStats(ctx)
+	for name, stats := range per {
+		if stats["pool_in_use"] != 1 {
+			t.Errorf("backend %s: in_use=%d, want 1 under round-robin", name, stats["pool_in_use"])
+		}
+	}
+	for _, res := range held {
+		if err := p.Put(res); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+}
+
+func TestBalancedPool_Failover(t *testing.T) {
+	bad := &switchConn{}
+	var downs, ups atomic.Int32
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	config.OnEndpointDown = func(name string, err error) {
+		if name == "bad" {
+			downs.Add(1)
+		}
+	}
+	config.OnEndpointUp = func(name string) {
+		if name == "bad" {
+			ups.Add(1)
+		}
+	}
+	p := startTestPool(t, NewBalancedPool(config, []Endpoint[*FakeConn]{
+		{Name: "good", Conn: &FakeConnControl{}},
+		{Name: "bad", Conn: bad},
+	}))
+	events, cancelSub := p.Subscribe(8)
+	defer cancelSub()
+
+	// 让 bad 的 Create 连续失败，越过失败率阈值
+	bad.down.Store(true)
+	ctx := context.Background()
+	// 持有连接并继续 Get，迫使 bad 子池扩容，Create 失败计入健康窗口
+	var held []*Resource[*FakeConn]
+	for i := 0; i < 20 && downs.Load() == 0; i++ {
+		tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		if res, err := p.Get(tctx); err == nil {
+			held = append(held, res)
+		}
+		cancel()
+	}
+	for _, res := range held {
+		p.Put(res)
+	}
+	if downs.Load() != 1 {
+		t.Fatalf("expected bad endpoint marked down once, got %d", downs.Load())
+	}
+
+	// 下线后所有 Get 都落在 good 上
+	for i := 0; i < 4; i++ {
+		tctx, cancel := context.WithTimeout(ctx, time.Second)
+		res, err := p.Get(tctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Get after failover failed: %v", err)
+		}
+		p.Put(res)
+	}
+	per, _ := p.BackendStats(ctx)
+	if per["bad"]["healthy"] != 0 {
+		t.Errorf("bad should be reported unhealthy, stats=%v", per["bad"])
+	}
+
+	// 恢复后探活成功重新上线
+	bad.down.Store(false)
+	time.Sleep(300 * time.Millisecond)
+	if ups.Load() != 1 {
+		t.Fatalf("expected bad endpoint back up once, got %d", ups.Load())
+	}
+	stats, _ := p.Stats(ctx)
+	if stats["healthy_backends"] != 2 {
+		t.Errorf("expected 2 healthy backends, got %d", stats["healthy_backends"])
+	}
+
+	for _, want := range []string{"bad open", "bad closed"} {
+		select {
+		case ev := <-events:
+			if ev.Kind != EventBreakerStateChanged || ev.Detail != want {
+				t.Errorf("event %v %q, want breaker_state_changed %q", ev.Kind, ev.Detail, want)
+			}
+		default:
+			t.Errorf("missing breaker event %q", want)
+		}
+	}
+}
+
+func TestBalancers(t *testing.T) {
+	backends := []BackendInfo{
+		{Name: "a", Weight: 1, InUse: 5},
+		{Name: "b", Weight: 0, InUse: 1},
+		{Name: "c", Weight: 8, InUse: 3},
+	}
+	if got := NewLeastInUseBalancer().Pick(backends); got != 1 {
+		t.Errorf("LeastInUse picked %d, want 1", got)
+	}
+	rr := NewRoundRobinBalancer()
+	for i := 0; i < 6; i++ {
+		if got := rr.Pick(backends); got != i%3 {
+			t.Errorf("RoundRobin pick %d = %d, want %d", i, got, i%3)
+		}
+	}
+	counts := make([]int, 3)
+	wr := NewWeightedRandomBalancer()
+	for i := 0; i < 10000; i++ {
+		counts[wr.Pick(backends)]++
+	}
+	// 权重 1:1(0 按 1 计):8
+	if counts[2] < 7000 || counts[0] < 500 || counts[1] < 500 {
+		t.Errorf("WeightedRandom distribution off: %v", counts)
+	}
+}
+
+func TestBalancedPool_NoHealthyBackend(t *testing.T) {
+	bad := &switchConn{}
+	bad.down.Store(true)
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	config.MinSize = 3 // preInit 的 3 次失败就足以下线
+	p := startTestPool(t, NewBalancedPool(config, []Endpoint[*FakeConn]{{Name: "bad", Conn: bad}}))
+
+	if _, err := p.Get(context.Background()); !errors.Is(err, ErrNoHealthyBackend) {
+		t.Fatalf("expected ErrNoHealthyBackend, got %v", err)
+	}
+}
+
+// countingConn 记录 Create 次数
+type countingConn struct {
+	FakeConnControl
+	creates atomic.Int64
+}
+
+func (c *countingConn) Create() (*FakeConn, error) {
+	c.creates.Add(1)
+	return c.FakeConnControl.Create()
+}
+
+// TestBalancedPool_RetireStopsExpanding 移除的后端在排空期间不再补建连接，借出的连接照常归还
+func TestBalancedPool_RetireStopsExpanding(t *testing.T) {
+	a := &countingConn{}
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	config.DrainTimeout = 5 * time.Second
+	p := startTestPool(t, NewBalancedPool(config, []Endpoint[*FakeConn]{{Name: "a", Conn: a}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	lease, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if err := p.Reconcile([]Endpoint[*FakeConn]{{Name: "b", Conn: &FakeConnControl{}}}); err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	before := a.creates.Load()
+	time.Sleep(300 * time.Millisecond)
+	if got := a.creates.Load(); got != before {
+		t.Errorf("retired backend created %d connections while draining", got-before)
+	}
+	if err := p.Put(lease); err != nil {
+		t.Fatalf("Put of lease from removed backend failed: %v", err)
+	}
+}
+
+// TestBalancedPool_LabeledConn 后端实现了 LabeledConn 时子池照常记录标签
+func TestBalancedPool_LabeledConn(t *testing.T) {
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewBalancedPool(config, []Endpoint[*FakeConn]{{Name: "a", Conn: &labeledControl{}}}))
+
+	res, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer p.Put(res)
+	if res.Labels()["mode"] != "rw" {
+		t.Errorf("labels = %v, want mode=rw from CreateLabeled", res.Labels())
+	}
+}
@@ -0,0 +1,185 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestGetN_Basic 验证 GetN 一次拿到 n 个不同的连接，PutN 全部归还
+func TestGetN_Basic(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(5, 10), &FakeConnControl{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	batch, err := p.GetN(ctx, 4)
+	if err != nil {
+		t.Fatalf("GetN failed: %v", err)
+	}
+	if len(batch) != 4 {
+		t.Fatalf("expected 4 resources, got %d", len(batch))
+	}
+	seen := make(map[*FakeConn]bool)
+	for _, r := range batch {
+		if seen[r.Conn] {
+			t.Fatal("GetN returned the same connection twice")
+		}
+		seen[r.Conn] = true
+	}
+
+	stats, _ := p.Stats(ctx)
+	if stats["pool_in_use"] != 4 {
+		t.Errorf("expected pool_in_use=4, got %d", stats["pool_in_use"])
+	}
+	if err := p.PutN(batch); err != nil {
+		t.Fatalf("PutN failed: %v", err)
+	}
+	stats, _ = p.Stats(ctx)
+	if stats["pool_in_use"] != 0 {
+		t.Errorf("expected pool_in_use=0 after PutN, got %d", stats["pool_in_use"])
+	}
+}
+
+// TestGetN_TooLarge 验证超过 MaxSize 的批量请求立即失败
+func TestGetN_TooLarge(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 4), &FakeConnControl{}))
+
+	if _, err := p.GetN(context.Background(), 5); !errors.Is(err, ErrBatchTooLarge) {
+		t.Fatalf("expected ErrBatchTooLarge, got %v", err)
+	}
+}
+
+// TestGetN_TimeoutHoldsNothing 验证超时返回时不持有任何连接
+func TestGetN_TimeoutHoldsNothing(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(4, 4), &FakeConnControl{}))
+
+	held, err := p.GetN(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("GetN failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetN(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+
+	stats, _ := p.Stats(context.Background())
+	if stats["pool_available"] != 2 {
+		t.Errorf("timed-out GetN should hold nothing, available=%d", stats["pool_available"])
+	}
+	if stats["waiting_demand"] != 0 {
+		t.Errorf("expected waiting_demand=0, got %d", stats["waiting_demand"])
+	}
+	p.PutN(held)
+}
+
+// TestGetN_NoDeadlock 验证多个批量任务争抢时不会互相持有部分连接导致死锁
+func TestGetN_NoDeadlock(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(6, 6), &FakeConnControl{}))
+
+	const jobs = 8
+	const rounds = 20
+	var wg sync.WaitGroup
+	errCh := make(chan error, jobs)
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+				batch, err := p.GetN(ctx, 4)
+				cancel()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				time.Sleep(time.Millisecond)
+				p.PutN(batch)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Errorf("GetN failed under contention: %v", err)
+	}
+
+	stats, _ := p.Stats(context.Background())
+	if stats["pool_in_use"] != 0 {
+		t.Errorf("expected pool_in_use=0, got %d", stats["pool_in_use"])
+	}
+}
+
+// TestGetN_ValidationFailedChecksIn 批量校验失败时，已通过校验的资源交还前恢复为空闲状态
+func TestGetN_ValidationFailedChecksIn(t *testing.T) {
+	ctrl := &FakeConnControl{}
+	config := testConfig(2, 2)
+	config.ReconnectOnGet = true
+	config.LeakTracking = true
+	p := startTestPool(t, NewPool(config, ctrl))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	held, err := p.GetN(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetN failed: %v", err)
+	}
+	held[1].Conn.pingErr = errors.New("connection lost")
+	p.PutN(held)
+
+	ctrl.failRate = 1.0
+	if _, err := p.GetN(ctx, 2); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("expected ErrValidationFailed, got %v", err)
+	}
+	snap, _ := p.Snapshot()
+	for _, info := range snap {
+		if info.ID == held[0].ID && (info.State != ResourceIdle || info.Holder != "") {
+			t.Errorf("resource %s: state=%v holder=%q, want idle with no holder", info.ID, info.State, info.Holder)
+		}
+	}
+}
+
+// TestGetN_PriorityNoDeadlock 高优先级的批量请求不能抢走低优先级批量请求已凑了一半的剩余名额，
+// 否则两边各持一部分，一直等到超时
+func TestGetN_PriorityNoDeadlock(t *testing.T) {
+	config := testConfig(4, 4)
+	config.WaitQueueMode = WaitQueuePriority
+	config.PriorityAging = time.Hour
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	held, err := p.GetN(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("GetN failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	errs := make(chan error, 2)
+	run := func(prio int) {
+		batch, err := p.GetN(WithPriority(ctx, prio), 3)
+		if err == nil {
+			time.Sleep(20 * time.Millisecond)
+			err = p.PutN(batch)
+		}
+		errs <- err
+	}
+
+	go run(0)
+	time.Sleep(50 * time.Millisecond)
+	p.PutN(held[:2]) // 低优先级批量请求拿到 2 个
+	time.Sleep(50 * time.Millisecond)
+	go run(10)
+	time.Sleep(50 * time.Millisecond)
+	p.PutN(held[2:])
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("batch %d failed: %v", i, err)
+		}
+	}
+}
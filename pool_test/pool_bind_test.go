@@ -0,0 +1,133 @@
+package pool_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestBind MaxSize=1 时嵌套 Get 也不会死锁，最后一次 release 才真正归还
+func TestBind(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 1), &FakeConnControl{}))
+
+	ctx, release := p.Bind(context.Background())
+	outer, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	// 子 context 和可重入的 Bind 都拿到同一个资源
+	child, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	nestedCtx, nestedRelease := p.Bind(child)
+	inner, err := p.Get(nestedCtx)
+	if err != nil {
+		t.Fatalf("nested Get failed: %v", err)
+	}
+	if inner != outer {
+		t.Fatal("nested Get should return the bound resource")
+	}
+	p.Put(inner)
+	nestedRelease()
+	nestedRelease() // 重复调用无效
+
+	p.Put(outer)
+	stats, _ := p.Stats(ctx)
+	if stats["pool_in_use"] != 1 {
+		t.Fatalf("pool_in_use=%d before final release, want 1", stats["pool_in_use"])
+	}
+
+	release()
+	stats, _ = p.Stats(ctx)
+	if stats["pool_in_use"] != 0 {
+		t.Fatalf("pool_in_use=%d after final release, want 0", stats["pool_in_use"])
+	}
+
+	// 释放后的 context 退回普通 Get
+	res, err := p.GetTimeout(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Get after release failed: %v", err)
+	}
+	p.Put(res)
+}
+
+// TestBind_Lazy Bind 后没有 Get 时 release 不归还任何东西
+func TestBind_Lazy(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &FakeConnControl{}))
+
+	_, release := p.Bind(context.Background())
+	release()
+	stats, _ := p.Stats(context.Background())
+	if stats["pool_in_use"] != 0 || stats["total_size"] != 1 {
+		t.Fatalf("in_use=%d total=%d, want 0 and 1", stats["pool_in_use"], stats["total_size"])
+	}
+}
+
+// TestBind_ReleaseWhileWaiting 第一次 Get 排队期间 release 不会被阻塞，排到的资源按普通 Get 归还
+func TestBind_ReleaseWhileWaiting(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 1), &FakeConnControl{}))
+	held, err := p.GetTimeout(100 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	ctx, release := p.Bind(context.Background())
+	got := make(chan *Resource[*FakeConn], 1)
+	go func() {
+		r, err := p.Get(ctx)
+		if err != nil {
+			t.Errorf("bound Get failed: %v", err)
+		}
+		got <- r
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	released := make(chan struct{})
+	go func() {
+		release()
+		close(released)
+	}()
+	select {
+	case <-released:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("release blocked while the bound Get was waiting")
+	}
+
+	p.Put(held)
+	res := <-got
+	if res == nil {
+		t.Fatal("bound Get returned no resource")
+	}
+	p.Put(res)
+	stats, _ := p.Stats(context.Background())
+	if stats["pool_in_use"] != 0 {
+		t.Fatalf("pool_in_use=%d after Put, want 0", stats["pool_in_use"])
+	}
+}
+
+// TestBind_ExtraPut Put 多于 Get 时提前归还，之后的 release 不会把引用减成负数
+func TestBind_ExtraPut(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 1), &FakeConnControl{}))
+
+	ctx, release := p.Bind(context.Background())
+	res, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	p.Put(res)
+	p.Put(res) // 多出来的 Put 释放了 Bind 的那次引用，资源提前归还
+	release()
+
+	// 绑定已全部释放：退回普通 Get，Put 之后真正归还
+	res, err = p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get after release failed: %v", err)
+	}
+	p.Put(res)
+	stats, _ := p.Stats(ctx)
+	if stats["pool_in_use"] != 0 {
+		t.Fatalf("pool_in_use=%d after Put, want 0", stats["pool_in_use"])
+	}
+}
@@ -0,0 +1,189 @@
+package pool_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// trackingConn 记录被关闭的连接
+type trackingConn struct {
+	FakeConnControl
+	closed sync.Map
+}
+
+func (c *trackingConn) Close(conn *FakeConn) error {
+	c.closed.Store(conn, true)
+	return c.FakeConnControl.Close(conn)
+}
+
+func waitBackends(t *testing.T, p *DiscoveryPool[*FakeConn], want ...string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		per, err := p.BackendStats(context.Background())
+		if err != nil {
+			t.Fatalf("BackendStats failed: %v", err)
+		}
+		ok := len(per) == len(want)
+		for _, name := range want {
+			if _, found := per[name]; !found {
+				ok = false
+			}
+		}
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backends = %v, want %v", per, want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestDiscoveryPool_Static 增删后端时，删除前借出的连接仍可正常归还，归还后被关闭
+func TestDiscoveryPool_Static(t *testing.T) {
+	a := &trackingConn{}
+	d := NewStaticDiscovery(DiscoveredEndpoint{Addr: "a"})
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	config.DrainTimeout = 5 * time.Second
+	p, err := NewDiscoveryPool(config, d, func(addr string) Conn[*FakeConn] {
+		if addr == "a" {
+			return a
+		}
+		return &FakeConnControl{}
+	})
+	if err != nil {
+		t.Fatalf("NewDiscoveryPool failed: %v", err)
+	}
+	defer p.Close()
+	waitBackends(t, p, "a")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	lease, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	d.Set(DiscoveredEndpoint{Addr: "b"})
+	waitBackends(t, p, "b")
+
+	// 新的 Get 只会落到 b 上
+	res, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get after reconcile failed: %v", err)
+	}
+	p.Put(res)
+	per, _ := p.BackendStats(ctx)
+	if per["b"]["pool_in_use"] != 0 {
+		t.Errorf("b in_use=%d after Put, want 0", per["b"]["pool_in_use"])
+	}
+
+	if err := p.Put(lease); err != nil {
+		t.Fatalf("Put of lease from removed backend failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := a.closed.Load(lease.Conn); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("connection of removed backend not closed after drain")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestFileDiscovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("# backends\na 2\nb\n")
+	d := NewFileDiscovery(path, 20*time.Millisecond)
+	defer d.Close()
+
+	eps, err := d.Endpoints()
+	if err != nil || len(eps) != 2 || eps[0] != (DiscoveredEndpoint{Addr: "a", Weight: 2}) || eps[1].Addr != "b" {
+		t.Fatalf("Endpoints = %v, %v", eps, err)
+	}
+
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	p, err := NewDiscoveryPool(config, d, func(string) Conn[*FakeConn] { return &FakeConnControl{} })
+	if err != nil {
+		t.Fatalf("NewDiscoveryPool failed: %v", err)
+	}
+	defer p.Close()
+	waitBackends(t, p, "a", "b")
+
+	write("b\nc 3\n")
+	waitBackends(t, p, "b", "c")
+
+	// 解析失败时保留当前后端
+	write("c bad-weight\n")
+	time.Sleep(100 * time.Millisecond)
+	if _, err := d.Endpoints(); err == nil {
+		t.Error("expected parse error for invalid weight")
+	}
+	waitBackends(t, p, "b", "c")
+}
+
+// TestDiscoveryPool_DuplicateAddrs 重复的地址只建一个后端
+func TestDiscoveryPool_DuplicateAddrs(t *testing.T) {
+	d := NewStaticDiscovery(
+		DiscoveredEndpoint{Addr: "a", Weight: 2},
+		DiscoveredEndpoint{Addr: "a", Weight: 5},
+		DiscoveredEndpoint{Addr: "b"},
+	)
+	config := BalancedPoolConfig{
+		PoolConfig:    testConfig(2, 4),
+		FailureWindow: 10,
+		MinSamples:    3,
+		ProbeInterval: 50 * time.Millisecond,
+	}
+	config.MonitorInterval = 50 * time.Millisecond
+	var mu sync.Mutex
+	created := make(map[string]int)
+	p, err := NewDiscoveryPool(config, d, func(addr string) Conn[*FakeConn] {
+		mu.Lock()
+		created[addr]++
+		mu.Unlock()
+		return &FakeConnControl{}
+	})
+	if err != nil {
+		t.Fatalf("NewDiscoveryPool failed: %v", err)
+	}
+	defer p.Close()
+	waitBackends(t, p, "a", "b")
+
+	d.Set(DiscoveredEndpoint{Addr: "b"}, DiscoveredEndpoint{Addr: "c"}, DiscoveredEndpoint{Addr: "c"})
+	waitBackends(t, p, "b", "c")
+
+	mu.Lock()
+	defer mu.Unlock()
+	for addr, n := range created {
+		if n != 1 {
+			t.Errorf("%s: %d backends created, want 1", addr, n)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+func TestRecentEvents(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 4), &FakeConnControl{}))
+
+	p.Pause()
+	p.Resume()
+	if err := p.Resize(1, 3); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+
+	events, err := p.RecentEvents()
+	if err != nil {
+		t.Fatalf("RecentEvents failed: %v", err)
+	}
+	var kinds []EventKind
+	for _, ev := range events {
+		kinds = append(kinds, ev.Kind)
+	}
+	want := []EventKind{EventCreated, EventCreated, EventPaused, EventResumed, EventConfigChanged}
+	if len(kinds) != len(want) {
+		t.Fatalf("events %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("events %v, want %v", kinds, want)
+		}
+	}
+	if events[4].Detail != "min=1 max=3" {
+		t.Errorf("resize detail %q", events[4].Detail)
+	}
+
+	p.Close()
+	if _, err := p.RecentEvents(); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("RecentEvents after Close: got %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &FakeConnControl{}))
+
+	res, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	p.Put(res)
+
+	lat, err := p.Latency()
+	if err != nil {
+		t.Fatalf("Latency failed: %v", err)
+	}
+	if lat.Wait.Count != 1 || lat.Hold.Count != 1 {
+		t.Fatalf("counts wait=%d hold=%d, want 1 each", lat.Wait.Count, lat.Hold.Count)
+	}
+	if q := lat.Hold.Quantile(0.5); q < 30*time.Millisecond || q > 50*time.Millisecond {
+		t.Errorf("hold p50 = %v, want the 50ms bucket", q)
+	}
+	if lat.Hold.Mean() < 30*time.Millisecond {
+		t.Errorf("hold mean = %v, want >= 30ms", lat.Hold.Mean())
+	}
+}
+
+// TestSubscribe 订阅者按顺序收到扩容、超时、缩容等事件，cancel 后 channel 关闭
+func TestSubscribe(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &FakeConnControl{}))
+	events, cancel := p.Subscribe(32)
+	ctx := context.Background()
+
+	r1, _ := p.Get(ctx)
+	r2, err := p.Get(ctx) // 触发扩容
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	tctx, tcancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	if _, err := p.Get(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get on full pool: got %v, want DeadlineExceeded", err)
+	}
+	tcancel()
+	p.Put(r1)
+	p.Put(r2)
+	if err := p.Resize(1, 1); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+
+	want := []EventKind{EventExpanded, EventCreated, EventWaiterTimedOut, EventConfigChanged, EventClosed}
+	var got []Event
+	timeout := time.After(time.Second)
+	for len(got) < len(want) {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("got %d events %v, want %v", len(got), got, want)
+		}
+	}
+	for i, ev := range got {
+		if ev.Kind != want[i] {
+			t.Fatalf("event %d is %v, want %v (all: %v)", i, ev.Kind, want[i], got)
+		}
+	}
+	if got[0].N != 1 || got[4].Detail != ReasonResize {
+		t.Errorf("expanded N=%d, closed reason %q", got[0].N, got[4].Detail)
+	}
+
+	cancel()
+	cancel() // 重复调用是安全的
+	if _, ok := <-events; ok {
+		t.Error("channel should be closed after cancel")
+	}
+}
+
+// TestSubscribe_SlowSubscriber 订阅者不消费时事件被丢弃并计数，不阻塞池
+func TestSubscribe_SlowSubscriber(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &FakeConnControl{}))
+	slow, _ := p.Subscribe(1)
+
+	for i := 0; i < 5; i++ {
+		p.Pause()
+		p.Resume()
+	}
+	stats, _ := p.Stats(context.Background())
+	if stats["events_dropped"] != 9 {
+		t.Errorf("events_dropped = %d, want 9", stats["events_dropped"])
+	}
+	if ev := <-slow; ev.Kind != EventPaused {
+		t.Errorf("first buffered event %v, want paused", ev.Kind)
+	}
+
+	p.Close()
+	// Close 关闭空闲连接的事件可能还在缓冲里，读完后 channel 应已关闭
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-slow:
+		case <-timeout:
+			t.Fatal("channel should be closed after pool Close")
+		}
+	}
+	late, _ := p.Subscribe(1)
+	if _, ok := <-late; ok {
+		t.Error("Subscribe on a closed pool should return a closed channel")
+	}
+}
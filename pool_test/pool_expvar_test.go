@@ -0,0 +1,70 @@
+package pool_test
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+type expvarView struct {
+	Name    string           `json:"name"`
+	Closed  bool             `json:"closed"`
+	Stats   map[string]int64 `json:"stats"`
+	GetWait struct {
+		Count int64 `json:"count"`
+	} `json:"get_wait"`
+}
+
+func readExpvar(t *testing.T, name string) expvarView {
+	t.Helper()
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatalf("expvar %q not published", name)
+	}
+	var view expvarView
+	if err := json.Unmarshal([]byte(v.String()), &view); err != nil {
+		t.Fatalf("expvar %q is not valid JSON: %v", name, err)
+	}
+	return view
+}
+
+// TestPublishExpvar 重复发布同名变量时切换到新的池，不 panic
+func TestPublishExpvar(t *testing.T) {
+	config := testConfig(2, 4)
+	config.Name = "first"
+	first := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	if err := first.PublishExpvar("pool_expvar_test"); err != nil {
+		t.Fatalf("PublishExpvar failed: %v", err)
+	}
+	view := readExpvar(t, "pool_expvar_test")
+	if view.Name != "first" || view.Closed || view.Stats["total_size"] != 2 {
+		t.Errorf("expvar = %+v", view)
+	}
+
+	first.Close()
+	if view := readExpvar(t, "pool_expvar_test"); !view.Closed {
+		t.Errorf("expvar after Close = %+v, want closed", view)
+	}
+
+	config.Name = "second"
+	second := NewPool(config, &FakeConnControl{})
+	defer second.Close()
+	if err := second.PublishExpvar("pool_expvar_test"); err != nil {
+		t.Fatalf("re-publishing failed: %v", err)
+	}
+	if view := readExpvar(t, "pool_expvar_test"); view.Name != "second" || view.Closed {
+		t.Errorf("expvar after re-publish = %+v, want second pool", view)
+	}
+
+	expvar.NewInt("pool_expvar_taken")
+	if err := second.PublishExpvar("pool_expvar_taken"); !errors.Is(err, ErrExpvarConflict) {
+		t.Errorf("publishing over a foreign var: got %v, want ErrExpvarConflict", err)
+	}
+	if err := first.PublishExpvar("pool_expvar_other"); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("publishing a closed pool: got %v, want ErrPoolClosed", err)
+	}
+}
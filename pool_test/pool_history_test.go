@@ -0,0 +1,108 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestHistory 每个监控周期记录一个采样，速率只统计本周期
+func TestHistory(t *testing.T) {
+	config := testConfig(1, 1)
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	held, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		if _, err := p.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Get on exhausted pool: got %v, want DeadlineExceeded", err)
+		}
+		cancel()
+	}
+	time.Sleep(100 * time.Millisecond)
+	p.Put(held)
+	time.Sleep(200 * time.Millisecond)
+
+	samples, err := p.History(0)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(samples) < 6 {
+		t.Fatalf("%d samples, want at least 6", len(samples))
+	}
+	var gets, timeouts float64
+	var sawInUse bool
+	for i, s := range samples {
+		if i > 0 && !s.Time.After(samples[i-1].Time) {
+			t.Fatalf("samples out of order at %d", i)
+		}
+		gets += s.GetsPerSec
+		timeouts += s.TimeoutsPerSec
+		sawInUse = sawInUse || s.InUse == 1
+	}
+	if gets == 0 || timeouts == 0 || !sawInUse {
+		t.Errorf("gets=%.1f/s timeouts=%.1f/s sawInUse=%v, want all non-zero", gets, timeouts, sawInUse)
+	}
+	if last := samples[len(samples)-1]; last.InUse != 0 || last.TimeoutsPerSec != 0 || last.Total != 1 {
+		t.Errorf("last sample %+v, want idle pool with no timeouts", last)
+	}
+
+	recent, _ := p.History(120 * time.Millisecond)
+	if len(recent) == 0 || len(recent) >= len(samples) {
+		t.Errorf("History(120ms) returned %d of %d samples", len(recent), len(samples))
+	}
+
+	p.Close()
+	if _, err := p.History(0); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("History after Close: got %v, want ErrPoolClosed", err)
+	}
+}
+
+// TestHistory_AllGetters TryGet、GetN、GetMatching 成功的获取同样计入获取等待和 gets/s
+func TestHistory_AllGetters(t *testing.T) {
+	config := testConfig(4, 4)
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewPool(config, &labeledControl{}))
+	ctx := context.Background()
+
+	var held []*Resource[*FakeConn]
+	if res, ok := p.TryGet(); ok {
+		held = append(held, res)
+	} else {
+		t.Fatal("TryGet missed on a warm pool")
+	}
+	batch, err := p.GetN(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetN failed: %v", err)
+	}
+	held = append(held, batch...)
+	res, err := p.GetMatching(ctx, func(l Labels) bool { return l.Match(Labels{"mode": "rw"}) })
+	if err != nil {
+		t.Fatalf("GetMatching failed: %v", err)
+	}
+	held = append(held, res)
+	for _, r := range held {
+		p.Put(r)
+	}
+
+	lat, _ := p.Latency()
+	if lat.Wait.Count != 4 {
+		t.Errorf("wait count = %d, want 4 (1 TryGet + 2 GetN + 1 GetMatching)", lat.Wait.Count)
+	}
+	time.Sleep(100 * time.Millisecond)
+	samples, _ := p.History(0)
+	var gets float64
+	for _, s := range samples {
+		gets += s.GetsPerSec
+	}
+	if gets == 0 {
+		t.Error("gets/s stayed zero although every getter succeeded")
+	}
+}
@@ -0,0 +1,238 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+func fakeConnFor(string) Conn[*FakeConn] { return &FakeConnControl{} }
+
+func TestKeyedPool_LazyPerKey(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 3)}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, key := range []string{"redis-a", "redis-b"} {
+		res, err := p.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		p.Put(key, res)
+	}
+
+	perKey, err := p.KeyStats(ctx)
+	if err != nil {
+		t.Fatalf("KeyStats failed: %v", err)
+	}
+	if len(perKey) != 2 {
+		t.Fatalf("expected 2 keyed pools, got %d", len(perKey))
+	}
+	for key, stats := range perKey {
+		if stats["total_size"] < 1 || stats["total_size"] > 3 {
+			t.Errorf("%s: total_size %d out of [1,3]", key, stats["total_size"])
+		}
+	}
+}
+
+// TestKeyedPool_PerKeyMaxSize 单个 key 耗尽时新的 Get 超时，不会向其他 key 借连接
+func TestKeyedPool_PerKeyMaxSize(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2)}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx := context.Background()
+
+	var held []*Resource[*FakeConn]
+	for i := 0; i < 2; i++ {
+		res, err := p.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get %d failed: %v", i, err)
+		}
+		held = append(held, res)
+	}
+	tctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	if _, err := p.Get(tctx, "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded beyond per-key MaxSize, got %v", err)
+	}
+	for _, res := range held {
+		p.Put("a", res)
+	}
+}
+
+// TestKeyedPool_GlobalBudget 全局预算用满后，新 key 通过回收其他 key 的空闲连接拿到连接
+func TestKeyedPool_GlobalBudget(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 4), MaxTotalSize: 4}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// 把 key a 撑到 4 个再全部归还
+	var held []*Resource[*FakeConn]
+	for i := 0; i < 4; i++ {
+		res, err := p.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get a/%d failed: %v", i, err)
+		}
+		held = append(held, res)
+	}
+	for _, res := range held {
+		p.Put("a", res)
+	}
+
+	res, err := p.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Get b failed under exhausted budget: %v", err)
+	}
+	p.Put("b", res)
+
+	stats, _ := p.Stats(ctx)
+	t.Logf("stats=%v", stats)
+	if stats["total_size"] > 4 {
+		t.Errorf("total_size %d exceeds MaxTotalSize 4", stats["total_size"])
+	}
+}
+
+// TestKeyedPool_PreInitBudget 新 key 预建 MinSize 个连接时同样受全局预算限制
+func TestKeyedPool_PreInitBudget(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(5, 5), MaxTotalSize: 6}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	for _, key := range []string{"a", "b", "c"} {
+		res, err := p.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		p.Put(key, res)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	stats, _ := p.Stats(ctx)
+	if stats["total_size"] > 6 {
+		t.Errorf("total_size %d exceeds MaxTotalSize 6", stats["total_size"])
+	}
+}
+
+func TestKeyedPool_IdleTTL(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2), KeyIdleTTL: 100 * time.Millisecond}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("k%d", i)
+		res, err := p.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		p.Put(key, res)
+	}
+	time.Sleep(400 * time.Millisecond)
+
+	stats, _ := p.Stats(ctx)
+	if stats["keys"] != 0 || stats["evicted_keys"] != 3 {
+		t.Fatalf("expected all 3 keys evicted, got keys=%d evicted=%d", stats["keys"], stats["evicted_keys"])
+	}
+
+	// 被回收的 key 再次 Get 会重新创建
+	res, err := p.Get(ctx, "k0")
+	if err != nil {
+		t.Fatalf("Get after eviction failed: %v", err)
+	}
+	p.Put("k0", res)
+}
+
+func TestKeyedPool_Closed(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2)}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	p.Close()
+	if _, err := p.Get(context.Background(), "a"); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+// TestKeyedPool_PutAfterClose 关闭后归还的连接由所属子池关闭，不会泄漏
+func TestKeyedPool_PutAfterClose(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2)}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	res, err := p.Get(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	p.Close()
+
+	if err := p.Put("a", res); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+	if !res.Conn.closed {
+		t.Error("connection returned after Close should be closed")
+	}
+}
+
+// TestKeyedPool_PutWrongKey 用别的 key 归还时报错，连接不进入任何子池
+func TestKeyedPool_PutWrongKey(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2)}
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	ctx := context.Background()
+	res, err := p.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := p.Get(ctx, "b"); err != nil {
+		t.Fatalf("Get(b) failed: %v", err)
+	}
+
+	if err := p.Put("b", res); !errors.Is(err, ErrKeyMismatch) {
+		t.Fatalf("expected ErrKeyMismatch, got %v", err)
+	}
+	perKey, _ := p.KeyStats(ctx)
+	if perKey["a"]["pool_in_use"] != 1 {
+		t.Errorf("a: in_use = %d, want 1 after rejected Put", perKey["a"]["pool_in_use"])
+	}
+	if res.Conn.closed {
+		t.Error("connection rejected by Put should stay open")
+	}
+	if err := p.Put("a", res); err != nil {
+		t.Fatalf("Put with the right key failed: %v", err)
+	}
+}
+
+// TestKeyedPool_History 共享的监控 ticker 为每个子池记录 History 采样
+func TestKeyedPool_History(t *testing.T) {
+	config := KeyedPoolConfig{PoolConfig: testConfig(1, 2)}
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewKeyedPool(config, fakeConnFor))
+	for _, key := range []string{"a", "b"} {
+		res, err := p.Get(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", key, err)
+		}
+		p.Put(key, res)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	for _, key := range []string{"a", "b"} {
+		samples, err := p.KeyHistory(key, 0)
+		if err != nil {
+			t.Fatalf("KeyHistory(%s) failed: %v", key, err)
+		}
+		if len(samples) < 2 {
+			t.Errorf("%s: %d samples, want at least 2", key, len(samples))
+		}
+	}
+	if samples, _ := p.KeyHistory("missing", 0); samples != nil {
+		t.Errorf("unknown key: got %d samples, want nil", len(samples))
+	}
+}
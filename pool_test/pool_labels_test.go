@@ -0,0 +1,202 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// labeledControl 能建出 labeledBackends 中的任意一种连接，不指定时建第一种
+type labeledControl struct {
+	FakeConnControl
+	labeled atomic.Int32
+	closes  atomic.Int32
+}
+
+var labeledBackends = []Labels{{"mode": "rw"}, {"mode": "ro"}, {"tls": "on"}}
+
+func (c *labeledControl) CreateLabeled(selector func(Labels) bool) (*FakeConn, Labels, error) {
+	if selector == nil {
+		conn, err := c.FakeConnControl.Create()
+		return conn, labeledBackends[0], err
+	}
+	c.labeled.Add(1)
+	for _, l := range labeledBackends {
+		if selector(l) {
+			conn, err := c.FakeConnControl.Create()
+			return conn, l, err
+		}
+	}
+	return nil, nil, errors.New("no such backend")
+}
+
+func (c *labeledControl) Close(conn *FakeConn) error {
+	c.closes.Add(1)
+	return c.FakeConnControl.Close(conn)
+}
+
+func TestGetMatching(t *testing.T) {
+	cc := &labeledControl{}
+	p := startTestPool(t, NewPool(testConfig(2, 3), cc))
+	ctx := context.Background()
+	ro := Labels{"mode": "ro"}
+
+	// 没有匹配的空闲资源，有空位：按标签新建
+	res, err := p.GetMatching(ctx, func(l Labels) bool { return l.Match(ro) })
+	if err != nil {
+		t.Fatalf("GetMatching failed: %v", err)
+	}
+	if res.Labels()["mode"] != "ro" || cc.labeled.Load() != 1 {
+		t.Fatalf("labels=%v created=%d, want mode=ro and 1 labeled create", res.Labels(), cc.labeled.Load())
+	}
+	p.Put(res)
+
+	// 归还后再次匹配直接复用
+	again, err := p.GetMatching(ctx, func(l Labels) bool { return l.Match(ro) })
+	if err != nil {
+		t.Fatalf("GetMatching failed: %v", err)
+	}
+	if again.Conn != res.Conn {
+		t.Error("expected the idle ro connection to be reused")
+	}
+	p.Put(again)
+
+	// 持有者事后打上的标签同样可以被挑选
+	plain, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	// 预建的连接同样带着 LabeledConn 给出的标签
+	if plain.Labels()["mode"] != "rw" {
+		t.Fatalf("FIFO Get should hand out an older preInit connection labelled mode=rw, got %v", plain.Labels())
+	}
+	plain.SetLabel("db", "orders")
+	p.Put(plain)
+	got, err := p.GetMatching(ctx, func(l Labels) bool { return l["db"] == "orders" })
+	if err != nil {
+		t.Fatalf("GetMatching by later label failed: %v", err)
+	}
+	if got.Conn != plain.Conn {
+		t.Error("expected the connection labelled db=orders")
+	}
+	p.Put(got)
+}
+
+// TestGetMatching_FullPool 池满时把一个不匹配的连接就地替换成匹配的，总数不变
+func TestGetMatching_FullPool(t *testing.T) {
+	cc := &labeledControl{}
+	p := startTestPool(t, NewPool(testConfig(2, 2), cc))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tls := Labels{"tls": "on"}
+	res, err := p.GetMatching(ctx, func(l Labels) bool { return l.Match(tls) })
+	if err != nil {
+		t.Fatalf("GetMatching failed: %v", err)
+	}
+	if res.Labels()["tls"] != "on" {
+		t.Errorf("labels=%v, want tls=on", res.Labels())
+	}
+	p.Put(res)
+
+	stats, _ := p.Stats(ctx)
+	if stats["total_size"] != 2 || cc.closes.Load() != 1 {
+		t.Errorf("total_size=%d closes=%d, want 2 and 1", stats["total_size"], cc.closes.Load())
+	}
+}
+
+// TestGetMatching_Unsatisfiable 连接控制器建不出满足 selector 的连接时返回错误，名额不被占用
+func TestGetMatching_Unsatisfiable(t *testing.T) {
+	cc := &labeledControl{}
+	p := startTestPool(t, NewPool(testConfig(1, 2), cc))
+	ctx := context.Background()
+
+	_, err := p.GetMatching(ctx, func(l Labels) bool { return l["db"] == "audit" })
+	if !errors.Is(err, ErrCreateFailed) {
+		t.Fatalf("expected ErrCreateFailed, got %v", err)
+	}
+	stats, _ := p.Stats(ctx)
+	if stats["total_size"] != 1 || stats["expanding"] != 0 {
+		t.Errorf("total_size=%d expanding=%d, want 1 and 0", stats["total_size"], stats["expanding"])
+	}
+}
+
+func TestGetMatching_NoLabeledConn(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &FakeConnControl{}))
+	_, err := p.GetMatching(context.Background(), func(l Labels) bool { return l["mode"] == "ro" })
+	if !errors.Is(err, ErrNoMatchingResource) {
+		t.Fatalf("expected ErrNoMatchingResource, got %v", err)
+	}
+}
+
+// TestGetMatching_WaiterDuringScan 扫描空闲集合期间排队的 Get 能拿到扫描后放回的连接，
+// 而不是在池已满时一直等到超时
+func TestGetMatching_WaiterDuringScan(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 2), &FakeConnControl{}))
+
+	got := make(chan error, 1)
+	var calls atomic.Int32
+	res, err := p.GetMatching(context.Background(), func(Labels) bool {
+		// 第一个不匹配、第二个匹配；看第二个时 chanIdle 的 RemoveIf 已把 channel 倒空，这个 Get 只能排队
+		if calls.Add(1) == 2 {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				r, err := p.Get(ctx)
+				if err == nil {
+					p.Put(r)
+				}
+				got <- err
+			}()
+			time.Sleep(100 * time.Millisecond)
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		t.Fatalf("GetMatching failed: %v", err)
+	}
+	defer p.Put(res)
+
+	if err := <-got; err != nil {
+		t.Fatalf("Get queued during the idle scan failed: %v", err)
+	}
+}
+
+// TestGetMatching_DuringDrain Drain 期间不按标签新建连接，排队等 Drain 结束后重建的连接
+func TestGetMatching_DuringDrain(t *testing.T) {
+	cc := &labeledControl{}
+	p := startTestPool(t, NewPool(testConfig(1, 3), cc))
+	ctx := context.Background()
+	held, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.Drain(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	ro := func(l Labels) bool { return l.Match(Labels{"mode": "ro"}) }
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if _, err := p.GetMatching(short, ro); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetMatching during drain: got %v, want DeadlineExceeded", err)
+	}
+	if n := cc.labeled.Load(); n != 0 {
+		t.Fatalf("%d labeled connections created during drain, want 0", n)
+	}
+
+	p.Put(held)
+	if err := <-done; err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	res, err := p.GetMatching(ctx, ro)
+	if err != nil {
+		t.Fatalf("GetMatching after drain failed: %v", err)
+	}
+	p.Put(res)
+}
@@ -0,0 +1,106 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestLease_Revoke 超期未续约的租约被收回，名额交给等待者，之后的 Release 无效
+func TestLease_Revoke(t *testing.T) {
+	config := testConfig(1, 1)
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	ctx := context.Background()
+
+	lease, err := p.Acquire(ctx, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// MaxSize=1：等待者只能等租约被收回
+	tctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	res, err := p.Get(tctx)
+	if err != nil {
+		t.Fatalf("waiter did not get the reclaimed slot: %v", err)
+	}
+	select {
+	case <-lease.Expired():
+	case <-time.After(time.Second):
+		t.Fatal("Expired should be closed after revocation")
+	}
+	if err := lease.Release(); err != nil {
+		t.Errorf("Release after revocation should be a no-op, got %v", err)
+	}
+	if err := lease.Renew(time.Second); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Renew after revocation: expected ErrLeaseExpired, got %v", err)
+	}
+	p.Put(res)
+
+	stats, _ := p.Stats(ctx)
+	if stats["revoked_leases"] != 1 || stats["pool_in_use"] != 0 || stats["total_size"] != 1 {
+		t.Errorf("revoked=%d in_use=%d total=%d, want 1, 0, 1",
+			stats["revoked_leases"], stats["pool_in_use"], stats["total_size"])
+	}
+}
+
+func TestLease_Renew(t *testing.T) {
+	config := testConfig(1, 1)
+	config.MonitorInterval = 50 * time.Millisecond
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	lease, err := p.Acquire(context.Background(), 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if err := lease.Renew(100 * time.Millisecond); err != nil {
+			t.Fatalf("Renew %d failed: %v", i, err)
+		}
+	}
+	select {
+	case <-lease.Expired():
+		t.Fatal("renewed lease should not expire")
+	default:
+	}
+	if err := lease.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	stats, _ := p.Stats(context.Background())
+	if stats["pool_in_use"] != 0 || stats["revoked_leases"] != 0 {
+		t.Errorf("in_use=%d revoked=%d, want 0 and 0", stats["pool_in_use"], stats["revoked_leases"])
+	}
+}
+
+func TestLease_Discard(t *testing.T) {
+	var reported atomic.Int32
+	config := testConfig(1, 2)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.OnUnhealthy = func(error) { reported.Add(1) }
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	lease, err := p.Acquire(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	conn := lease.Conn()
+	lease.Discard(errors.New("protocol desync"))
+	lease.Discard(nil) // 重复调用无效
+
+	time.Sleep(100 * time.Millisecond)
+	if reported.Load() != 1 {
+		t.Errorf("OnUnhealthy called %d times, want 1", reported.Load())
+	}
+	res, err := p.GetTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("Get after Discard failed: %v", err)
+	}
+	if res.Conn == conn {
+		t.Error("discarded connection handed out again")
+	}
+	p.Put(res)
+}
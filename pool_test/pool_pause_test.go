@@ -0,0 +1,171 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+func TestPauseResume_Queue(t *testing.T) {
+	config := testConfig(2, 4)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.PauseMode = PauseQueue
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	p.Pause()
+	if !p.IsPaused() {
+		t.Fatal("IsPaused should be true after Pause")
+	}
+
+	tctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := p.Get(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get while paused: expected DeadlineExceeded, got %v", err)
+	}
+
+	got := make(chan error, 1)
+	go func() {
+		res, err := p.GetTimeout(2 * time.Second)
+		if err == nil {
+			p.Put(res)
+		}
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		t.Fatalf("Get returned while paused: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	p.Resume()
+	if err := <-got; err != nil {
+		t.Fatalf("Get after Resume failed: %v", err)
+	}
+}
+
+func TestPause_Fail(t *testing.T) {
+	config := testConfig(2, 4)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.PauseMode = PauseFail
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	p.Pause()
+	if _, err := p.Get(context.Background()); !errors.Is(err, ErrPoolPaused) {
+		t.Fatalf("expected ErrPoolPaused, got %v", err)
+	}
+	if _, ok := p.TryGet(); ok {
+		t.Fatal("TryGet should fail while paused")
+	}
+	p.Resume()
+	res, err := p.GetTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("Get after Resume failed: %v", err)
+	}
+	p.Put(res)
+}
+
+// TestDrain 空闲连接立即关闭，借出的连接归还时关闭，之后重建 MinSize 个新连接
+func TestDrain(t *testing.T) {
+	cc := &trackingConn{}
+	config := testConfig(2, 4)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.PauseMode = PauseQueue
+	p := startTestPool(t, NewPool(config, cc))
+	ctx := context.Background()
+
+	held, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	idle, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	p.Put(idle)
+
+	dctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.Drain(dctx) }()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := cc.closed.Load(idle.Conn); !ok {
+		t.Error("idle connection should be closed as soon as Drain starts")
+	}
+	if err := p.Drain(ctx); !errors.Is(err, ErrDrainInProgress) {
+		t.Errorf("concurrent Drain: expected ErrDrainInProgress, got %v", err)
+	}
+	if err := p.Put(held); err != nil {
+		t.Fatalf("Put during drain failed: %v", err)
+	}
+	if _, ok := cc.closed.Load(held.Conn); !ok {
+		t.Error("connection returned during drain should be closed")
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats, _ := p.Stats(ctx)
+		if stats["total_size"] == 2 && stats["draining"] == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pool not refilled to MinSize after drain: %v", stats)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	res, err := p.GetTimeout(time.Second)
+	if err != nil {
+		t.Fatalf("Get after drain failed: %v", err)
+	}
+	if _, ok := cc.closed.Load(res.Conn); ok || res.Conn == held.Conn || res.Conn == idle.Conn {
+		t.Error("Get after drain returned an old connection")
+	}
+	p.Put(res)
+}
+
+// TestDrain_Unpaused 不 Pause 直接 Drain：期间的 Get 排队，拿到的是重建后的新连接
+func TestDrain_Unpaused(t *testing.T) {
+	cc := &trackingConn{}
+	p := startTestPool(t, NewPool(testConfig(1, 2), cc))
+	ctx := context.Background()
+	held, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	dctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- p.Drain(dctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	got := make(chan *Resource[*FakeConn], 1)
+	go func() {
+		res, err := p.Get(dctx)
+		if err != nil {
+			t.Errorf("Get during drain failed: %v", err)
+		}
+		got <- res
+	}()
+	select {
+	case <-got:
+		t.Fatal("Get during drain should wait for the rebuilt connections")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	p.Put(held)
+	if err := <-done; err != nil {
+		t.Fatalf("Drain failed: %v", err)
+	}
+	res := <-got
+	if res == nil {
+		return
+	}
+	if _, ok := cc.closed.Load(res.Conn); ok || res.Conn == held.Conn {
+		t.Error("Get during drain received an old connection")
+	}
+	p.Put(res)
+}
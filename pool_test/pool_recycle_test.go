@@ -0,0 +1,206 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestRecycleAll 空闲连接按节奏替换，借出的在归还时关闭，连接总数基本不变
+func TestRecycleAll(t *testing.T) {
+	cc := &trackingConn{}
+	p := startTestPool(t, NewPool(testConfig(4, 6), cc))
+	ctx := context.Background()
+
+	held, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var reports []RecycleProgress
+	var minTotal int64 = 1 << 62
+	rctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	type result struct {
+		progress RecycleProgress
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		progress, err := p.RecycleAll(rctx, RecycleOptions{
+			Rate:     1,
+			Interval: 20 * time.Millisecond,
+			OnProgress: func(pr RecycleProgress) {
+				stats, _ := p.Stats(ctx)
+				mu.Lock()
+				reports = append(reports, pr)
+				minTotal = min(minTotal, stats["total_size"])
+				mu.Unlock()
+			},
+		})
+		done <- result{progress, err}
+	}()
+
+	// 只剩借出的那个旧连接时还不能结束
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(reports)
+		last := RecycleProgress{}
+		if n > 0 {
+			last = reports[n-1]
+		}
+		mu.Unlock()
+		if n > 0 && last.Remaining == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("idle connections not recycled, last progress %+v", last)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := p.RecycleAll(ctx, RecycleOptions{}); !errors.Is(err, ErrRecycleInProgress) {
+		t.Errorf("concurrent RecycleAll: expected ErrRecycleInProgress, got %v", err)
+	}
+	p.Put(held)
+	if _, ok := cc.closed.Load(held.Conn); !ok {
+		t.Error("in-use connection should be closed on Put after RecycleAll")
+	}
+
+	r := <-done
+	if r.err != nil {
+		t.Fatalf("RecycleAll failed: %v", r.err)
+	}
+	if r.progress.Remaining != 0 || r.progress.Replaced != r.progress.Total {
+		t.Errorf("final progress %+v, want everything replaced", r.progress)
+	}
+	mu.Lock()
+	if minTotal < 3 {
+		t.Errorf("total_size dropped to %d during rolling recycle", minTotal)
+	}
+	mu.Unlock()
+
+	time.Sleep(100 * time.Millisecond)
+	var fresh []*Resource[*FakeConn]
+	for i := 0; i < 4; i++ {
+		res, err := p.GetTimeout(time.Second)
+		if err != nil {
+			t.Fatalf("Get after recycle failed: %v", err)
+		}
+		if _, ok := cc.closed.Load(res.Conn); ok {
+			t.Error("Get after recycle returned a closed connection")
+		}
+		fresh = append(fresh, res)
+	}
+	for _, res := range fresh {
+		p.Put(res)
+	}
+}
+
+// TestRecycleAll_StaleCheckedOutLater 换代之后才被借出的旧连接也要等它归还，RecycleAll 才算完成
+func TestRecycleAll_StaleCheckedOutLater(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(3, 3), &FakeConnControl{}))
+
+	held, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	var mu sync.Mutex
+	var grabbed []*Resource[*FakeConn]
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		first := true
+		_, err := p.RecycleAll(ctx, RecycleOptions{
+			Rate:     1,
+			Interval: 20 * time.Millisecond,
+			OnProgress: func(RecycleProgress) {
+				if !first {
+					return
+				}
+				first = false
+				mu.Lock()
+				defer mu.Unlock()
+				// 第一轮只替换了一个，剩下的旧空闲连接在这里被借走；换代时借出的那个随即归还
+				for {
+					r, ok := p.TryGet()
+					if !ok {
+						break
+					}
+					grabbed = append(grabbed, r)
+				}
+				p.Put(held)
+			},
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("RecycleAll finished while a stale connection was still checked out: %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+	mu.Lock()
+	for _, r := range grabbed {
+		p.Put(r)
+	}
+	mu.Unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("RecycleAll failed: %v", err)
+	}
+}
+
+// slowConn 建连接需要 delay
+type slowConn struct {
+	FakeConnControl
+	delay time.Duration
+}
+
+func (c *slowConn) Create() (*FakeConn, error) {
+	time.Sleep(c.delay)
+	return c.FakeConnControl.Create()
+}
+
+// TestRecycleAll_DuringExpand 扩容中的连接因换代被丢弃时补建一个，排队的 Get 不会挂到超时
+func TestRecycleAll_DuringExpand(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(1, 2), &slowConn{delay: 100 * time.Millisecond}))
+	ctx := context.Background()
+	held, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	got := make(chan error, 1)
+	go func() {
+		gctx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		res, err := p.Get(gctx)
+		if err == nil {
+			p.Put(res)
+		}
+		got <- err
+	}()
+	time.Sleep(30 * time.Millisecond) // 扩容已开始，连接还没建好
+
+	rctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.RecycleAll(rctx, RecycleOptions{Interval: 10 * time.Millisecond})
+		done <- err
+	}()
+
+	if err := <-got; err != nil {
+		t.Fatalf("Get waiting on the discarded expansion failed: %v", err)
+	}
+	p.Put(held)
+	if err := <-done; err != nil {
+		t.Fatalf("RecycleAll failed: %v", err)
+	}
+}
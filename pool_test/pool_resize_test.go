@@ -0,0 +1,43 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestResize 调大 MinSize 立即补建，调小 MaxSize 立即关闭多余的空闲连接
+func TestResize(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 6), &FakeConnControl{}))
+	ctx := context.Background()
+
+	if err := p.Resize(4, 6); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	stats, _ := p.Stats(ctx)
+	if stats["total_size"] != 4 || stats["min_size"] != 4 {
+		t.Errorf("after raising MinSize: total=%d min=%d, want 4", stats["total_size"], stats["min_size"])
+	}
+
+	if err := p.Resize(1, 2); err != nil {
+		t.Fatalf("Resize failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	stats, _ = p.Stats(ctx)
+	if stats["total_size"] != 2 || stats["max_size"] != 2 {
+		t.Errorf("after lowering MaxSize: total=%d max=%d, want 2", stats["total_size"], stats["max_size"])
+	}
+	if _, err := p.GetN(ctx, 3); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("GetN above new MaxSize: got %v, want ErrBatchTooLarge", err)
+	}
+
+	for _, tc := range [][2]int64{{3, 2}, {0, 0}, {1, 7}, {-1, 2}} {
+		if err := p.Resize(tc[0], tc[1]); !errors.Is(err, ErrInvalidSize) {
+			t.Errorf("Resize(%d, %d): got %v, want ErrInvalidSize", tc[0], tc[1], err)
+		}
+	}
+}
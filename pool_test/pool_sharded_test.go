@@ -0,0 +1,146 @@
+package pool_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestShardedPool_GlobalMaxSize 验证所有分片共享一份 MaxSize 预算
+func TestShardedPool_GlobalMaxSize(t *testing.T) {
+	config := testConfig(4, 12)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.MaxWaitQueue = 100000
+	p := startTestPool(t, NewShardedPool(config, &FakeConnControl{}, 4))
+
+	var (
+		wg      sync.WaitGroup
+		holding atomic.Int64
+		peak    atomic.Int64
+		failed  atomic.Int64
+	)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				res, err := p.Get(ctx)
+				cancel()
+				if err != nil {
+					failed.Add(1)
+					continue
+				}
+				if n := holding.Add(1); n > peak.Load() {
+					peak.Store(n)
+				}
+				time.Sleep(100 * time.Microsecond)
+				holding.Add(-1)
+				p.Put(res)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, err := p.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	t.Logf("stats=%v peak=%d failed=%d", stats, peak.Load(), failed.Load())
+	if failed.Load() > 0 {
+		t.Errorf("%d Gets failed", failed.Load())
+	}
+	if stats["total_size"] > 12 {
+		t.Errorf("total_size %d exceeds global MaxSize 12", stats["total_size"])
+	}
+	if peak.Load() > 12 {
+		t.Errorf("held %d connections at once, exceeds global MaxSize 12", peak.Load())
+	}
+	if stats["shards"] != 4 {
+		t.Errorf("expected 4 shards, got %d", stats["shards"])
+	}
+	// 容量配置报全局值，不按分片数放大
+	if stats["max_size"] != 12 || stats["min_size"] != 4 || stats["buffer_cap"] != 12 {
+		t.Errorf("max_size=%d min_size=%d buffer_cap=%d, want 12, 4 and 12",
+			stats["max_size"], stats["min_size"], stats["buffer_cap"])
+	}
+}
+
+// TestShardedPool_Steal 验证本地分片为空时能取到其他分片的空闲连接
+func TestShardedPool_Steal(t *testing.T) {
+	// MinSize=MaxSize=2 分到 4 个分片：只有两个分片有连接，预算已满，取第 2 个时必然要窃取或等迁移
+	config := testConfig(2, 2)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.MaxWaitQueue = 100000
+	p := startTestPool(t, NewShardedPool(config, &FakeConnControl{}, 4))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	held := make([]*Resource[*FakeConn], 0, 2)
+	for i := 0; i < 2; i++ {
+		res, err := p.Get(ctx)
+		if err != nil {
+			t.Fatalf("Get %d failed: %v", i, err)
+		}
+		held = append(held, res)
+	}
+
+	// 池已耗尽：归还一个，排队中的 Get 必须拿到它
+	done := make(chan error, 1)
+	go func() {
+		res, err := p.Get(ctx)
+		if err == nil {
+			p.Put(res)
+		}
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	p.Put(held[0])
+	if err := <-done; err != nil {
+		t.Fatalf("waiting Get failed: %v", err)
+	}
+	p.Put(held[1])
+
+	stats, _ := p.Stats(context.Background())
+	if stats["total_size"] != 2 {
+		t.Errorf("expected total_size 2, got %d", stats["total_size"])
+	}
+}
+
+func TestShardedPool_Closed(t *testing.T) {
+	config := testConfig(2, 4)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.MaxWaitQueue = 100000
+	p := startTestPool(t, NewShardedPool(config, &FakeConnControl{}, 2))
+	p.Close()
+	if _, err := p.Get(context.Background()); err == nil {
+		t.Fatal("expected error from closed pool")
+	}
+	if !p.IsClosed() {
+		t.Error("IsClosed should report true")
+	}
+}
+
+// BenchmarkShardedPool_GetPut 与 BenchmarkPool_GetPut 同配置，对比分片后的调度开销
+func BenchmarkShardedPool_GetPut(b *testing.B) {
+	config := testConfig(50, 100)
+	config.MonitorInterval = 50 * time.Millisecond
+	config.MaxWaitQueue = 100000
+	p := startTestPool(b, NewShardedPool(config, &FakeConnControl{}, 0))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			res, err := p.Get(ctx)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = p.Put(res)
+		}
+	})
+}
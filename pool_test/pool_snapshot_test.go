@@ -0,0 +1,84 @@
+package pool_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+func TestSnapshot(t *testing.T) {
+	config := testConfig(3, 3)
+	config.LeakTracking = true
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+
+	res, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if res.State() != ResourceInUse || res.UseCount() != 1 || res.CreatedAt().IsZero() {
+		t.Errorf("accessors: state=%v uses=%d created=%v", res.State(), res.UseCount(), res.CreatedAt())
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if len(snap) != 3 {
+		t.Fatalf("snapshot has %d resources, want 3", len(snap))
+	}
+	inUse := 0
+	for _, info := range snap {
+		if info.State != ResourceInUse {
+			continue
+		}
+		inUse++
+		if info.ID != res.ID || !strings.Contains(info.Holder, "pool_snapshot_test.go") {
+			t.Errorf("in-use entry %+v, want ID %s held by this test", info, res.ID)
+		}
+	}
+	if inUse != 1 {
+		t.Errorf("%d in-use entries, want 1", inUse)
+	}
+
+	p.Put(res)
+	snap, _ = p.Snapshot()
+	for _, info := range snap {
+		if info.State != ResourceIdle || info.Holder != "" {
+			t.Errorf("after Put: %+v, want idle with no holder", info)
+		}
+	}
+}
+
+// TestSnapshot_Concurrent Snapshot 与 Get/Put 并发时不阻塞、无数据竞争（配合 -race）
+func TestSnapshot_Concurrent(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(4, 8), &FakeConnControl{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				if res, err := p.Get(ctx); err == nil {
+					p.Put(res)
+				}
+			}
+		}()
+	}
+	for ctx.Err() == nil {
+		snap, err := p.Snapshot()
+		if err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+		if len(snap) == 0 || len(snap) > 8 {
+			t.Fatalf("snapshot has %d resources, want 1..8", len(snap))
+		}
+	}
+	wg.Wait()
+}
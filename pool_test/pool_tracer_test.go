@@ -0,0 +1,68 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+func TestTracer(t *testing.T) {
+	tracer := NewRecordingTracer()
+	config := testConfig(1, 2)
+	config.Name = "traced"
+	config.PingInterval = 50 * time.Millisecond
+	config.Tracer = tracer
+	p := startTestPool(t, NewPool(config, &FakeConnControl{}))
+	ctx := context.Background()
+
+	if pings := tracer.Spans(SpanPing); len(pings) == 0 || pings[0].Attrs[AttrResource] != "init-0" {
+		t.Errorf("ping spans = %+v, want spans on init-0", pings)
+	}
+
+	r1, _ := p.Get(ctx)
+	r2, err := p.Get(ctx) // 排队等扩容
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	tctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	if _, err := p.Get(tctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get on full pool: got %v, want DeadlineExceeded", err)
+	}
+	cancel()
+	p.Put(r1)
+	p.Put(r2)
+
+	creates := tracer.Spans(SpanCreate)
+	if len(creates) != 2 {
+		t.Fatalf("%d create spans, want 2", len(creates))
+	}
+	for _, s := range creates {
+		if s.End.IsZero() || s.Err != nil || s.Attrs[AttrAttempt] != 1 || s.Attrs[AttrPool] != "traced" {
+			t.Errorf("create span %+v", s)
+		}
+	}
+
+	waits := tracer.Spans(SpanGetWait)
+	if len(waits) != 2 {
+		t.Fatalf("%d wait spans, want 2", len(waits))
+	}
+	if waits[0].Err != nil || waits[0].Attrs[AttrDemand] != 1 {
+		t.Errorf("queued Get span %+v, want success with demand 1", waits[0])
+	}
+	if !errors.Is(waits[1].Err, context.DeadlineExceeded) || waits[1].Duration() < 20*time.Millisecond {
+		t.Errorf("timed-out Get span err=%v duration=%v", waits[1].Err, waits[1].Duration())
+	}
+
+	resets := tracer.Spans(SpanReset)
+	if len(resets) != 2 || resets[0].Attrs[AttrResource] != r1.ID || resets[1].Attrs[AttrResource] != r2.ID {
+		t.Errorf("reset spans = %+v", resets)
+	}
+
+	tracer.Reset()
+	if len(tracer.Spans(SpanCreate)) != 0 {
+		t.Error("Reset should clear recorded spans")
+	}
+}
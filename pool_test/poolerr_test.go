@@ -0,0 +1,167 @@
+package pool_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/RedHuang-0622/TemplatePoolByGO"
+)
+
+// TestPoolError_ErrorsIs 验证 PoolError 同时匹配哨兵错误与底层原因
+func TestPoolError_ErrorsIs(t *testing.T) {
+	cause := errors.New("broken pipe")
+	err := error(&PoolError{Op: "put", Pool: "orders", ResourceID: "init-0", Kind: ErrResetFailed, Err: cause})
+
+	if !errors.Is(err, ErrResetFailed) {
+		t.Error("expected errors.Is(err, ErrResetFailed)")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected errors.Is(err, cause)")
+	}
+	if errors.Is(err, ErrPoolClosed) {
+		t.Error("unexpected match with ErrPoolClosed")
+	}
+
+	var pe *PoolError
+	if !errors.As(err, &pe) || pe.ResourceID != "init-0" {
+		t.Fatalf("errors.As failed: %v", err)
+	}
+	msg := err.Error()
+	for _, part := range []string{`"orders"`, "put", "init-0", "broken pipe"} {
+		if !strings.Contains(msg, part) {
+			t.Errorf("error message %q missing %q", msg, part)
+		}
+	}
+}
+
+// TestPut_ResetFailedIsWrapped 验证 Put 时 Reset 失败返回 ErrResetFailed 且保留原因
+func TestPut_ResetFailedIsWrapped(t *testing.T) {
+	resetErr := errors.New("reset boom")
+	config := testConfig(1, 2)
+	config.Name = "reset-test"
+	p := startTestPool(t, NewPool(config, &FakeConnControl{resetErr: resetErr}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	err = p.Put(res)
+	if !errors.Is(err, ErrResetFailed) || !errors.Is(err, resetErr) {
+		t.Fatalf("expected ErrResetFailed wrapping cause, got %v", err)
+	}
+	var pe *PoolError
+	if !errors.As(err, &pe) || pe.Pool != "reset-test" || pe.ResourceID != res.ID {
+		t.Errorf("unexpected PoolError fields: %+v", pe)
+	}
+}
+
+// TestGet_ValidationFailed 验证 ReconnectOnGet 重连全部失败时返回 ErrValidationFailed
+func TestGet_ValidationFailed(t *testing.T) {
+	ctrl := &FakeConnControl{}
+	config := testConfig(1, 1)
+	config.MaxRetries = 1
+	config.ReconnectOnGet = true
+	p := startTestPool(t, NewPool(config, ctrl))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	res.Conn.pingErr = errors.New("connection lost")
+	if err := p.Put(res); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	ctrl.failRate = 1.0 // 之后所有 Create 都失败
+	_, err = p.Get(ctx)
+	if !errors.Is(err, ErrValidationFailed) || !errors.Is(err, ErrCreateFailed) {
+		t.Fatalf("expected ErrValidationFailed wrapping ErrCreateFailed, got %v", err)
+	}
+}
+
+// TestGet_ValidationFailedDropsConn 重连失败的连接被关闭而不是交给调用方，MaxRetries=0 时也重连一次，
+// Create 恢复后池子补回连接
+func TestGet_ValidationFailedDropsConn(t *testing.T) {
+	ctrl := &FakeConnControl{}
+	config := testConfig(1, 1)
+	config.ReconnectOnGet = true
+	p := startTestPool(t, NewPool(config, ctrl))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res, err := p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	dead := res.Conn
+	dead.pingErr = errors.New("connection lost")
+	p.Put(res)
+
+	ctrl.failRate = 1.0
+	if _, err := p.Get(ctx); !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("expected ErrValidationFailed, got %v", err)
+	}
+	if ctrl.callCount.Load() == 0 {
+		t.Error("MaxRetries=0 should still try to reconnect once")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !dead.closed {
+		t.Error("connection that failed validation should be closed")
+	}
+
+	ctrl.failRate = 0
+	res, err = p.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get after Create recovered failed: %v", err)
+	}
+	if res.Conn == dead {
+		t.Error("got the dead connection back")
+	}
+	p.Put(res)
+}
+
+// TestGet_ClosedPoolFailsFast 验证 Close 后 Get/Put/Stats 立即返回 ErrPoolClosed
+func TestGet_ClosedPoolFailsFast(t *testing.T) {
+	p := startTestPool(t, NewPool(testConfig(2, 4), &FakeConnControl{}))
+
+	res, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if p.IsClosed() {
+		t.Fatal("IsClosed should be false before Close")
+	}
+	p.Close()
+	p.Close() // 重复关闭不应 panic
+	if !p.IsClosed() {
+		t.Fatal("IsClosed should be true after Close")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = p.Get(ctx)
+	if !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Get on closed pool should fail fast, took %v", elapsed)
+	}
+	if err := p.Put(res); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed from Put, got %v", err)
+	}
+	if !res.Conn.closed {
+		t.Error("resource returned after Close should be closed")
+	}
+	if _, err := p.Stats(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed from Stats, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package pool
+
+import (
+	"context"
+
+	"github.com/RedHuang-0622/TemplatePoolByGO/util/request_queue"
+)
+
+type priorityKey struct{}
+
+// WithPriority 返回携带等待优先级的 ctx，数值越大越先被服务
+// 只在 WaitQueueMode=WaitQueuePriority 时生效，FIFO 队列会忽略它
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFrom 读取 ctx 中的优先级，未设置时为 0
+func PriorityFrom(ctx context.Context) int {
+	if v, ok := ctx.Value(priorityKey{}).(int); ok {
+		return v
+	}
+	return 0
+}
+
+// GetPriority 以指定优先级 Get，等价于 Get(WithPriority(ctx, priority))
+func (p *Pool[T]) GetPriority(ctx context.Context, priority int) (*resource[T], error) {
+	return p.Get(WithPriority(ctx, priority))
+}
+
+// newWaitQueue 按配置创建等待队列
+func newWaitQueue[T any](config PoolConfig) request_queue.WaitQueue[*resource[T]] {
+	if config.WaitQueueMode == WaitQueuePriority {
+		return request_queue.NewPriorityQueue[*resource[T]](config.PriorityAging)
+	}
+	return request_queue.NewLockFreeQueue[*resource[T]]()
+}
+
+// enqueue 入队一个需要 n 个资源的等待者
+// 优先级取自 ctx（仅优先级队列使用），截止时间取自 ctx.Deadline，过期后队列不再向它投递
+func (p *Pool[T]) enqueue(ctx context.Context, n int) *request_queue.LockFreeWaiter[*resource[T]] {
+	deadline, _ := ctx.Deadline()
+	return p.waitQueue.EnqueueWith(request_queue.EnqueueOptions{
+		N:        n,
+		Priority: PriorityFrom(ctx),
+		Deadline: deadline,
+	})
+}
@@ -0,0 +1,111 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RecycleOptions RecycleAll 的节奏控制
+type RecycleOptions struct {
+	Rate       int                   // 每个 Interval 替换的空闲连接数，<=0 时为 1
+	Interval   time.Duration         // 替换间隔，<=0 时为 100ms
+	OnProgress func(RecycleProgress) // 每轮替换后回调，可为空
+}
+
+// RecycleProgress RecycleAll 的进度
+type RecycleProgress struct {
+	Total     int64 // 开始时的连接数
+	Replaced  int64 // 已关闭的旧连接数
+	Remaining int64 // 仍在空闲集合或借出中的旧连接数
+}
+
+// RecycleAll 滚动替换池里的所有连接，例如数据库密码或 TLS 证书轮换之后：
+// 当前所有连接按代数标记为旧，空闲的按 Rate/Interval 逐批关闭并补建新连接，借出的在 Put 时关闭并补建，
+// 连接总数基本不变，不会出现排空重建那样的延迟尖刺。全部替换完或 ctx 结束时返回最后一次进度
+func (p *Pool[T]) RecycleAll(ctx context.Context, opts RecycleOptions) (RecycleProgress, error) {
+	if p.closed.Load() {
+		return RecycleProgress{}, p.newError("recycle", "", ErrPoolClosed, nil)
+	}
+	if !p.recycling.CompareAndSwap(false, true) {
+		return RecycleProgress{}, p.newError("recycle", "", ErrRecycleInProgress, nil)
+	}
+	defer p.recycling.Store(false)
+	if opts.Rate <= 0 {
+		opts.Rate = 1
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 100 * time.Millisecond
+	}
+
+	progress := RecycleProgress{Total: p.totalSize.Load()}
+	// 换代：现有连接都变成旧的，空闲的由下面逐批替换，借出的在 Put 时关闭
+	p.gen.Add(1)
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := p.recycleIdle(ctx, opts.Rate); err != nil {
+			return progress, err
+		}
+		progress.Remaining = p.staleCount()
+		progress.Replaced = max(progress.Total-progress.Remaining, 0)
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+		if progress.Remaining == 0 {
+			p.emit(EventRecycled, "", fmt.Sprintf("replaced=%d", progress.Replaced), nil)
+			return progress, nil
+		}
+		select {
+		case <-ctx.Done():
+			p.emit(EventRecycled, "", fmt.Sprintf("replaced=%d", progress.Replaced), ctx.Err())
+			return progress, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// recycleIdle 让 Actor 关闭最多 n 个旧的空闲连接并补建同样数量的新连接
+func (p *Pool[T]) recycleIdle(ctx context.Context, n int) error {
+	done := make(chan struct{})
+	if err := p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		defer close(done)
+		gen := a.gen.Load()
+		var closed int64
+		for _, r := range a.owner.removeIdle(func(r *resource[T]) bool {
+			if r.gen >= gen || closed >= int64(n) {
+				return false
+			}
+			closed++
+			return true
+		}) {
+			a.destroy(r, ReasonStale)
+		}
+		if closed > 0 && !a.draining {
+			a.expand(s, closed)
+		}
+	}); err != nil {
+		return p.newError("recycle", "", ErrActorStopped, err)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// staleCount 资源登记表中换代之前创建的连接数，空闲的和借出的都算：
+// 换代之后才被借出的旧连接同样要等它归还
+func (p *Pool[T]) staleCount() int64 {
+	gen := p.gen.Load()
+	var n int64
+	p.resources.Range(func(k, _ any) bool {
+		if k.(*resource[T]).gen < gen {
+			n++
+		}
+		return true
+	})
+	return n
+}
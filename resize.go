@@ -0,0 +1,45 @@
+package pool
+
+import "fmt"
+
+// Resize 运行期调整 MinSize / MaxSize，例如事故期间给下游数据库降压
+// max 不能超过空闲集合的容量（默认即创建时的 MaxSize）；调小 MaxSize 时超出部分的空闲连接立即关闭，
+// 借出中的在归还后由 shrink 回收；调大 MinSize 时立即补建（Pause / Drain 期间除外）
+// 返回时新的大小已经生效，多余的空闲连接已经关闭
+func (p *Pool[T]) Resize(min, max int64) error {
+	if p.closed.Load() {
+		return p.newError("resize", "", ErrPoolClosed, nil)
+	}
+	if limit := int64(p.idle.Cap()); min < 0 || max < 1 || min > max || max > limit {
+		return p.newError("resize", "", ErrInvalidSize, fmt.Errorf("min %d, max %d, limit %d", min, max, limit))
+	}
+	p.minSize.Store(min)
+	p.maxSize.Store(max)
+	done := make(chan struct{})
+	if err := p.manager.Send(func(a *PoolManagerActor[T], s *PoolManagerState[T]) {
+		defer close(done)
+		s.config.MinSize, s.config.MaxSize = min, max
+		a.emit(EventConfigChanged, "", fmt.Sprintf("min=%d max=%d", min, max), nil)
+		for excess := a.poolTotalSize.Load() - max; excess > 0; excess-- {
+			r, ok := a.idle.PopOldest()
+			if !ok {
+				break
+			}
+			a.destroy(r, ReasonResize)
+		}
+		if a.draining || a.gate.paused() {
+			return
+		}
+		if need := min - a.poolTotalSize.Load() - a.expanding.Load(); need > 0 {
+			a.expand(s, need)
+		}
+	}); err != nil {
+		return p.newError("resize", "", ErrActorStopped, err)
+	}
+	select {
+	case <-done:
+		return nil
+	case <-p.closeCtx.Done():
+		return p.newError("resize", "", ErrPoolClosed, nil)
+	}
+}
@@ -0,0 +1,181 @@
+package pool
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ResourceState 资源当前所处的状态
+type ResourceState int32
+
+const (
+	ResourceIdle     ResourceState = iota // 在空闲集合中（或正在交给等待者）
+	ResourceInUse                         // 已借出
+	ResourceCreating                      // 扩容中，连接还没建好
+	ResourceClosing                       // 正在关闭
+)
+
+func (s ResourceState) String() string {
+	switch s {
+	case ResourceIdle:
+		return "idle"
+	case ResourceInUse:
+		return "in-use"
+	case ResourceCreating:
+		return "creating"
+	case ResourceClosing:
+		return "closing"
+	}
+	return fmt.Sprintf("ResourceState(%d)", int32(s))
+}
+
+// CreatedAt 连接建立时间，重连后为新连接的建立时间
+func (r *Resource[T]) CreatedAt() time.Time { return time.Unix(0, r.createTime.Load()) }
+
+// LastUsedAt 最近一次被借出的时间
+func (r *Resource[T]) LastUsedAt() time.Time { return time.Unix(0, r.updateTime.Load()) }
+
+// UseCount 累计借出次数
+func (r *Resource[T]) UseCount() int64 { return r.useCount.Load() }
+
+// ReconnectCount 累计重连次数（ReconnectOnGet）
+func (r *Resource[T]) ReconnectCount() int64 { return r.retryCount.Load() }
+
+// State 资源当前状态
+func (r *Resource[T]) State() ResourceState { return ResourceState(r.state.Load()) }
+
+// LastError 最近一次 Ping / Reset / 校验失败或 Discard 时记录的错误
+func (r *Resource[T]) LastError() error {
+	if e := r.lastErr.Load(); e != nil {
+		return *e
+	}
+	return nil
+}
+
+// Holder 开启 LeakTracking 时为借出方的调用位置（file:line function），否则为空
+func (r *Resource[T]) Holder() string {
+	if h := r.holder.Load(); h != nil {
+		return *h
+	}
+	return ""
+}
+
+func (r *Resource[T]) setErr(err error) {
+	if err != nil {
+		r.lastErr.Store(&err)
+	}
+}
+
+// newResource 创建一个处于空闲状态的资源
+func newResource[T any](id string, conn T, owner *Pool[T], gen int64) *resource[T] {
+	r := &resource[T]{ID: id, Conn: conn, shard: owner.shard, owner: owner, gen: gen}
+	now := time.Now().UnixNano()
+	r.createTime.Store(now)
+	r.updateTime.Store(now)
+	return r
+}
+
+// track 登记一个新建的资源，Snapshot 据此枚举
+func (p *Pool[T]) track(r *resource[T]) {
+	p.resources.Store(r, struct{}{})
+}
+
+// destroy 关闭资源的连接、扣减总数并注销登记，reason 为 Reason* 常量之一
+func (p *Pool[T]) destroy(r *resource[T], reason string) {
+	r.state.Store(int32(ResourceClosing))
+	p.connControl.Close(r.Conn)
+	p.totalSize.Add(-1)
+	p.resources.Delete(r)
+	p.emit(EventClosed, r.ID, reason, nil)
+}
+
+// destroy Actor 侧的 Pool.destroy
+func (a *PoolManagerActor[T]) destroy(r *resource[T], reason string) {
+	r.state.Store(int32(ResourceClosing))
+	a.connControl.Close(r.Conn)
+	a.poolTotalSize.Add(-1)
+	a.resources.Delete(r)
+	a.emit(EventClosed, r.ID, reason, nil)
+}
+
+// checkout 资源交给调用方时更新统计
+func (p *Pool[T]) checkout(r *resource[T]) {
+	r.state.Store(int32(ResourceInUse))
+	r.updateTime.Store(time.Now().UnixNano())
+	r.useCount.Add(1)
+	if p.config.LeakTracking {
+		h := callerOutsidePackage()
+		r.holder.Store(&h)
+	}
+}
+
+// checkin 资源归还时更新统计
+func (p *Pool[T]) checkin(r *resource[T]) {
+	p.holdLatency.observe(time.Since(time.Unix(0, r.updateTime.Load())))
+	r.state.Store(int32(ResourceIdle))
+	if p.config.LeakTracking {
+		r.holder.Store(nil)
+	}
+}
+
+var pkgPrefix = reflect.TypeOf(PoolConfig{}).PkgPath() + "."
+
+// callerOutsidePackage 返回调用栈上第一个不属于本包的帧
+func callerOutsidePackage() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPrefix) {
+			return fmt.Sprintf("%s:%d %s", f.File, f.Line, f.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// ResourceInfo Snapshot 中一个资源的只读快照
+type ResourceInfo struct {
+	ID             string
+	State          ResourceState
+	CreatedAt      time.Time
+	LastUsedAt     time.Time
+	UseCount       int64
+	ReconnectCount int64
+	LastError      error
+	Holder         string // 仅 LeakTracking 开启时有值
+}
+
+// Snapshot 列出池里的每个资源（扩容中的以 ResourceCreating 占位），按创建时间排序
+// 只读原子字段，不加锁，不阻塞 Get/Put；各资源的状态不是同一时刻的
+func (p *Pool[T]) Snapshot() ([]ResourceInfo, error) {
+	if p.closed.Load() {
+		return nil, p.newError("snapshot", "", ErrPoolClosed, nil)
+	}
+	var out []ResourceInfo
+	p.resources.Range(func(k, _ any) bool {
+		r := k.(*resource[T])
+		out = append(out, ResourceInfo{
+			ID:             r.ID,
+			State:          r.State(),
+			CreatedAt:      r.CreatedAt(),
+			LastUsedAt:     r.LastUsedAt(),
+			UseCount:       r.UseCount(),
+			ReconnectCount: r.ReconnectCount(),
+			LastError:      r.LastError(),
+			Holder:         r.Holder(),
+		})
+		return true
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	for i := p.expanding.Load(); i > 0; i-- {
+		out = append(out, ResourceInfo{State: ResourceCreating})
+	}
+	return out, nil
+}
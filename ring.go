@@ -0,0 +1,37 @@
+package pool
+
+import "sync"
+
+// ring 定长环形缓冲，写满后覆盖最早的元素
+type ring[E any] struct {
+	mu   sync.Mutex
+	buf  []E
+	next int
+	full bool
+}
+
+func newRing[E any](size int) *ring[E] {
+	return &ring[E]{buf: make([]E, size)}
+}
+
+func (r *ring[E]) add(e E) {
+	r.mu.Lock()
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+// items 按写入先后返回缓冲中的元素
+func (r *ring[E]) items() []E {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]E(nil), r.buf[:r.next]...)
+	}
+	out := make([]E, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
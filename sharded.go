@@ -0,0 +1,208 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// sizeBudget 多个子池共享的 MaxSize 预算
+// 子池扩容时先在自己的 expanding 上占位，再检查所有子池 total+expanding 之和是否超额
+type sizeBudget[T any] struct {
+	max   int64
+	pools atomic.Pointer[[]*Pool[T]] // 全部子池创建完后才发布，此前一律视为已满
+}
+
+func (b *sizeBudget[T]) used() int64 {
+	pools := b.pools.Load()
+	if pools == nil {
+		return b.max
+	}
+	var n int64
+	for _, p := range *pools {
+		n += p.totalSize.Load() + p.expanding.Load()
+	}
+	return n
+}
+
+// exhausted 预算已用完（nil 预算不限制）
+func (b *sizeBudget[T]) exhausted() bool {
+	return b != nil && b.used() >= b.max
+}
+
+// allow 调用方已占位，检查占位后是否仍在预算内（nil 预算不限制）
+func (b *sizeBudget[T]) allow() bool {
+	return b == nil || b.used() <= b.max
+}
+
+// ShardedPool 把容量拆到 N 个子池上，降低极端并发下单个空闲集合和等待队列队头的竞争
+// 调用方优先走本地分片（借助 sync.Pool 的 per-P 缓存近似绑定到当前 P），
+// 本地为空时从其他分片窃取空闲连接，都没有才在本地分片排队；
+// 所有子池共享一份 MaxSize 预算，总连接数不会超过 PoolConfig.MaxSize
+type ShardedPool[T any] struct {
+	shards  []*Pool[T]
+	config  PoolConfig
+	budget  *sizeBudget[T]
+	hint    sync.Pool // 缓存 *int 分片下标，sync.Pool 按 P 本地存取
+	next    atomic.Uint64
+	waiting atomic.Int64 // 正在某个分片上排队的 Get 数，Put 据此决定是否跨分片迁移
+	closed  atomic.Bool
+
+	steals     atomic.Int64 // 从其他分片窃取到连接的次数
+	migrations atomic.Int64 // Put 时迁移到有等待者的分片的次数
+}
+
+// NewShardedPool 创建分片池，shards<=0 时取 GOMAXPROCS
+// MinSize 平均分到各分片；每个分片都可以增长到全局 MaxSize，由共享预算兜底；
+// IdleBufferFactor 按分片数折算，MaxWaitQueue、心跳和监控按分片各自生效
+func NewShardedPool[T any](config PoolConfig, connControl Conn[T], shards int) *ShardedPool[T] {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	sp := &ShardedPool[T]{
+		shards: make([]*Pool[T], shards),
+		config: config,
+		budget: &sizeBudget[T]{max: config.MaxSize},
+	}
+	for i := range sp.shards {
+		c := config
+		c.MinSize = config.MinSize / int64(shards)
+		if int64(i) < config.MinSize%int64(shards) {
+			c.MinSize++
+		}
+		c.IdleBufferFactor = config.IdleBufferFactor / float64(shards)
+		if config.Name != "" {
+			c.Name = fmt.Sprintf("%s#%d", config.Name, i)
+		}
+		sp.shards[i] = newPool(c, connControl, poolOptions[T]{budget: sp.budget, shard: i})
+	}
+	sp.budget.pools.Store(&sp.shards)
+	return sp
+}
+
+// local 返回当前调用方的本地分片下标
+func (sp *ShardedPool[T]) local() int {
+	if v, ok := sp.hint.Get().(*int); ok {
+		i := *v
+		sp.hint.Put(v)
+		return i
+	}
+	i := int(sp.next.Add(1) % uint64(len(sp.shards)))
+	sp.hint.Put(&i)
+	return i
+}
+
+func (sp *ShardedPool[T]) newError(op string, kind error) *PoolError {
+	return &PoolError{Op: op, Pool: sp.config.Name, Kind: kind}
+}
+
+// Get 本地分片 → 窃取其他分片的空闲连接 → 在本地分片排队
+// 排队前先登记 waiting，入队后再窃取一轮：登记之后的 Put 会迁移到有等待者的分片，
+// 登记之前已空闲的连接由这一轮窃取取走，不会出现别处有空闲而本地一直等的情况
+func (sp *ShardedPool[T]) Get(ctx context.Context) (*Resource[T], error) {
+	if sp.closed.Load() {
+		return nil, sp.newError("get", ErrPoolClosed)
+	}
+	home := sp.local()
+	if r, ok := sp.steal(home); ok {
+		return r, nil
+	}
+	sp.waiting.Add(1)
+	defer sp.waiting.Add(-1)
+	return sp.shards[home].get(ctx, func() (*Resource[T], bool) {
+		return sp.steal(home)
+	})
+}
+
+// steal 从 home 开始依次尝试各分片的空闲集合
+func (sp *ShardedPool[T]) steal(home int) (*Resource[T], bool) {
+	n := len(sp.shards)
+	for i := 0; i < n; i++ {
+		if r, ok := sp.shards[(home+i)%n].tryPop(); ok {
+			if i > 0 {
+				sp.steals.Add(1)
+			}
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Put 归还到所属分片；所属分片没人等而其他分片有人排队时，连同计数一起迁过去
+func (sp *ShardedPool[T]) Put(res *Resource[T]) error {
+	if res == nil {
+		return nil
+	}
+	owner := sp.shards[res.shard]
+	if !sp.closed.Load() && sp.waiting.Load() > 0 && owner.waitQueue.Len() == 0 {
+		n := len(sp.shards)
+		for i := 1; i < n; i++ {
+			idx := (res.shard + i) % n
+			target := sp.shards[idx]
+			if target.waitQueue.Len() == 0 {
+				continue
+			}
+			// 先加后减：预算求和只会高估
+			target.totalSize.Add(1)
+			target.inUse.Add(1)
+			owner.totalSize.Add(-1)
+			owner.inUse.Add(-1)
+			owner.resources.Delete(res)
+			target.track(res)
+			res.shard = idx
+			res.owner = target
+			sp.migrations.Add(1)
+			return target.Put(res)
+		}
+	}
+	return owner.Put(res)
+}
+
+// Stats 各分片计数求和，容量配置取全局值，paused/draining 任一分片为 1 即为 1；
+// 另附分片数、窃取和迁移次数
+func (sp *ShardedPool[T]) Stats(ctx context.Context) (map[string]int64, error) {
+	if sp.closed.Load() {
+		return nil, sp.newError("stats", ErrPoolClosed)
+	}
+	total := make(map[string]int64)
+	for _, p := range sp.shards {
+		stats, err := p.Stats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range stats {
+			switch k {
+			case "paused", "draining":
+				total[k] = max(total[k], v)
+			default:
+				total[k] += v
+			}
+		}
+	}
+	// 每个分片都按全局 MaxSize 开容量，求和没有意义
+	bufferCap := int64(float64(sp.config.MaxSize) * sp.config.IdleBufferFactor)
+	total["max_size"] = sp.config.MaxSize
+	total["min_size"] = sp.config.MinSize
+	total["buffer_cap"] = max(bufferCap, 1)
+	total["shards"] = int64(len(sp.shards))
+	total["steals"] = sp.steals.Load()
+	total["migrations"] = sp.migrations.Load()
+	return total, nil
+}
+
+// Close 关闭所有分片，重复调用是安全的
+func (sp *ShardedPool[T]) Close() {
+	if !sp.closed.CompareAndSwap(false, true) {
+		return
+	}
+	for _, p := range sp.shards {
+		p.Close()
+	}
+}
+
+// IsClosed 返回分片池是否已关闭
+func (sp *ShardedPool[T]) IsClosed() bool {
+	return sp.closed.Load()
+}
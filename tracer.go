@@ -0,0 +1,163 @@
+package pool
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// 池发出的 span 名称
+const (
+	SpanGetWait = "pool.get.wait" // Get / GetN 在等待队列中排队
+	SpanCreate  = "pool.create"   // expand / preInit 中的一次 Create
+	SpanPing    = "pool.ping"     // 心跳中的一次 Ping
+	SpanReset   = "pool.reset"    // Put 中的 Reset
+)
+
+// span 属性键
+const (
+	AttrPool     = "pool.name"
+	AttrResource = "pool.resource" // 资源 ID
+	AttrAttempt  = "pool.attempt"  // Create 的第几次尝试，从 1 开始
+	AttrDemand   = "pool.demand"   // 排队时请求的连接数
+	AttrWaiting  = "pool.waiting"  // 排队时等待队列的长度（含自己）
+)
+
+// Attr span 属性
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// Span 一个进行中的 span
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	End(err error) // err 为 nil 表示成功
+}
+
+// Tracer 链路追踪接入点，通过 PoolConfig.Tracer 配置；库本身不依赖任何追踪 SDK，
+// 需要 OpenTelemetry 时由调用方实现一个转发到 otel.Tracer 的适配器
+type Tracer interface {
+	StartSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// NoopTracer 什么也不记录，未配置 Tracer 时的默认值
+type NoopTracer struct{}
+
+func (NoopTracer) StartSpan(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr) {}
+func (noopSpan) End(error)             {}
+
+// tracing 池内部使用的 Tracer 包装：NoopTracer 时直接返回 noopSpan，热路径上不构造属性
+type tracing struct {
+	tracer  Tracer
+	enabled bool
+	pool    string
+}
+
+func newTracing(config PoolConfig) tracing {
+	if config.Tracer == nil {
+		return tracing{tracer: NoopTracer{}}
+	}
+	_, noop := config.Tracer.(NoopTracer)
+	return tracing{tracer: config.Tracer, enabled: !noop, pool: config.Name}
+}
+
+// start 开始一个 span，attrs 只在启用追踪时调用
+func (t tracing) start(ctx context.Context, name string, attrs func() []Attr) Span {
+	if !t.enabled {
+		return noopSpan{}
+	}
+	all := []Attr{{Key: AttrPool, Value: t.pool}}
+	if attrs != nil {
+		all = append(all, attrs()...)
+	}
+	_, span := t.tracer.StartSpan(ctx, name, all...)
+	return span
+}
+
+// RecordedSpan RecordingTracer 记录的一个 span
+type RecordedSpan struct {
+	Name  string
+	Attrs map[string]any
+	Start time.Time
+	End   time.Time // 未结束时为零值
+	Err   error
+}
+
+// Duration span 的耗时，未结束时为 0
+func (s RecordedSpan) Duration() time.Duration {
+	if s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// RecordingTracer 把 span 记录在内存里，供测试断言
+type RecordingTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// NewRecordingTracer 创建一个空的 RecordingTracer
+func NewRecordingTracer() *RecordingTracer { return &RecordingTracer{} }
+
+func (t *RecordingTracer) StartSpan(ctx context.Context, name string, attrs ...Attr) (context.Context, Span) {
+	s := &RecordedSpan{Name: name, Attrs: make(map[string]any, len(attrs)), Start: time.Now()}
+	for _, a := range attrs {
+		s.Attrs[a.Key] = a.Value
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return ctx, &recordingSpan{tracer: t, span: s}
+}
+
+// Spans 按开始顺序返回已记录 span 的副本；name 非空时只返回该名称的
+func (t *RecordingTracer) Spans(name string) []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []RecordedSpan
+	for _, s := range t.spans {
+		if name == "" || s.Name == name {
+			c := *s
+			c.Attrs = maps.Clone(s.Attrs)
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Reset 清空已记录的 span
+func (t *RecordingTracer) Reset() {
+	t.mu.Lock()
+	t.spans = nil
+	t.mu.Unlock()
+}
+
+type recordingSpan struct {
+	tracer *RecordingTracer
+	span   *RecordedSpan
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...Attr) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	for _, a := range attrs {
+		s.span.Attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordingSpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	if s.span.End.IsZero() {
+		s.span.End, s.span.Err = time.Now(), err
+	}
+}
@@ -2,6 +2,7 @@ package closure_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -278,8 +279,8 @@ func TestTrySendWhenFull(t *testing.T) {
 	err := actor.TrySend(func(a *CounterActor, s *int) {
 		a.Increment(s, 1)
 	})
-	if err == nil || err.Error() != "inbox is full" {
-		t.Errorf("expected 'inbox is full' error, got %v", err)
+	if !errors.Is(err, closure.ErrInboxFull) {
+		t.Errorf("expected ErrInboxFull, got %v", err)
 	}
 }
 
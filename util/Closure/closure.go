@@ -2,11 +2,18 @@ package closure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 )
 
+// 导出的哨兵错误，调用方可用 errors.Is 判断
+var (
+	ErrActorStopped = errors.New("actor is stopped")
+	ErrInboxFull    = errors.New("inbox is full")
+)
+
 // Actor 定义了 Actor 的基本行为接口
 type Actor[T any] interface {
 	// Init 初始化状态
@@ -131,7 +138,7 @@ func (c *Closure[T, A]) CallWithContext(ctx context.Context, fn func(A, *T) any)
 	// 优先检查是否已经停止，避免向已关闭的循环发送消息导致永久阻塞
 	select {
 	case <-c.stopped:
-		return nil, fmt.Errorf("actor is stopped")
+		return nil, ErrActorStopped
 	default:
 	}
 
@@ -152,7 +159,7 @@ func (c *Closure[T, A]) CallWithContext(ctx context.Context, fn func(A, *T) any)
 	case c.inbox <- task:
 		// 发送成功，继续等待结果
 	case <-c.stopped:
-		return nil, fmt.Errorf("actor is stopped")
+		return nil, ErrActorStopped
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
@@ -222,7 +229,7 @@ func (c *Closure[T, A]) Send(fn func(A, *T)) error {
 	case c.inbox <- task:
 		return nil
 	case <-c.stopped:
-		return fmt.Errorf("actor is stopped")
+		return ErrActorStopped
 	}
 }
 
@@ -242,9 +249,9 @@ func (c *Closure[T, A]) TrySend(fn func(A, *T)) error {
 	case c.inbox <- task:
 		return nil
 	case <-c.stopped:
-		return fmt.Errorf("actor is stopped")
+		return ErrActorStopped
 	default:
-		return fmt.Errorf("inbox is full")
+		return ErrInboxFull
 	}
 }
 
@@ -0,0 +1,237 @@
+package request_queue
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// PriorityQueue 按优先级分发资源的等待队列，遵循与 LockFreeQueue 相同的契约
+// 数值越大优先级越高；同优先级按入队顺序（FIFO）
+//
+// 老化（aging）：每等待 aging 时长，等效优先级 +1，低优先级等待者不会被无限饿死
+// 等效优先级 = priority + 等待时长/aging，两个等待者的差值与当前时间无关，
+// 所以入队时算一次分数即可，堆序不会随时间失效
+type PriorityQueue[T any] struct {
+	mu       sync.Mutex
+	items    pqHeap[T]
+	seq      uint64
+	aging    time.Duration
+	start    time.Time // 老化计时基准，让分数保持较小的量级
+	counters waitCounters
+
+	// pinned 已收到部分资源的批量等待者，凑满之前一直优先服务
+	// 否则更高优先级的批量等待者会抢走剩下的资源，两边各持一部分互相等到超时
+	pinned *LockFreeWaiter[T]
+}
+
+type pqItem[T any] struct {
+	w     *LockFreeWaiter[T]
+	score float64 // priority - 入队时刻/aging，越大越先服务
+	seq   uint64
+}
+
+type pqHeap[T any] []*pqItem[T]
+
+func (h pqHeap[T]) Len() int { return len(h) }
+func (h pqHeap[T]) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score > h[j].score
+	}
+	return h[i].seq < h[j].seq
+}
+func (h pqHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pqHeap[T]) Push(x any)   { *h = append(*h, x.(*pqItem[T])) }
+func (h *pqHeap[T]) Pop() any {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return it
+}
+
+// NewPriorityQueue 创建优先级队列，aging<=0 时关闭老化（严格按优先级）
+func NewPriorityQueue[T any](aging time.Duration) *PriorityQueue[T] {
+	return &PriorityQueue[T]{aging: aging, start: time.Now()}
+}
+
+// Enqueue 以默认优先级 0 入队
+func (q *PriorityQueue[T]) Enqueue() *LockFreeWaiter[T] {
+	return q.EnqueueWith(EnqueueOptions{})
+}
+
+// EnqueueN 以默认优先级 0 入队一个批量等待者
+func (q *PriorityQueue[T]) EnqueueN(n int) *LockFreeWaiter[T] {
+	return q.EnqueueWith(EnqueueOptions{N: n})
+}
+
+// EnqueuePriority 以指定优先级入队一个需要 n 个资源的等待者
+func (q *PriorityQueue[T]) EnqueuePriority(priority int, n int) *LockFreeWaiter[T] {
+	return q.EnqueueWith(EnqueueOptions{N: n, Priority: priority})
+}
+
+// EnqueueWith 按参数入队
+func (q *PriorityQueue[T]) EnqueueWith(opts EnqueueOptions) *LockFreeWaiter[T] {
+	n := opts.N
+	if n < 1 {
+		n = 1
+	}
+	w := newWaiter(make(chan T, n), opts)
+
+	score := float64(opts.Priority)
+	if q.aging > 0 {
+		score -= float64(time.Since(q.start)) / float64(q.aging)
+	}
+
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.items, &pqItem[T]{w: w, score: score, seq: q.seq})
+	admit(&q.counters, w)
+	q.mu.Unlock()
+	return w
+}
+
+// TryDequeue 把资源交给优先级最高的有效等待者
+// 已取消、已过截止时间的等待者会被弹出，不会收到资源；
+// 已收到部分资源的批量等待者优先于堆顶，直到凑满或取消
+func (q *PriorityQueue[T]) TryDequeue(resource T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var now int64
+	for w := q.pinned; w != nil; w = q.pinned {
+		if w.pending() && w.deadline != 0 {
+			if now == 0 {
+				now = time.Now().UnixNano()
+			}
+			reap(&q.counters, w, now)
+		}
+		if !w.pending() {
+			q.pinned = nil // 已凑满或已取消，节点留在堆里由下面延迟弹出
+			break
+		}
+		if deliverBatch(&q.counters, w, resource) {
+			if !w.pending() {
+				q.pinned = nil
+			}
+			return true
+		}
+	}
+	for len(q.items) > 0 {
+		w := q.items[0].w
+
+		if !w.pending() {
+			heap.Pop(&q.items)
+			continue
+		}
+		if w.deadline != 0 {
+			if now == 0 {
+				now = time.Now().UnixNano()
+			}
+			if reap(&q.counters, w, now) {
+				heap.Pop(&q.items)
+				continue
+			}
+		}
+
+		if w.isBatch() {
+			if deliverBatch(&q.counters, w, resource) {
+				if !w.pending() {
+					heap.Pop(&q.items)
+				} else {
+					q.pinned = w
+				}
+				return true
+			}
+			continue // 与 RemoveBatch 竞争，重新读取
+		}
+
+		heap.Pop(&q.items)
+		if retire(&q.counters, w, waiterDelivered) {
+			w.Ch <- resource // 容量为 1，只有抢占成功的一方写入
+			return true
+		}
+	}
+	return false
+}
+
+// Remove 取消等待者（延迟删除，与 LockFreeQueue 一致）
+// 返回 false 表示资源已投递，调用方需要从 Ch 取出并归还
+func (q *PriorityQueue[T]) Remove(w *LockFreeWaiter[T]) bool {
+	if w == nil {
+		return true
+	}
+	if w.isBatch() {
+		q.RemoveBatch(w)
+		return true
+	}
+	return remove(&q.counters, w)
+}
+
+// RemoveBatch 取消批量等待者，返回已被认领的资源数
+func (q *PriorityQueue[T]) RemoveBatch(w *LockFreeWaiter[T]) int {
+	if w == nil {
+		return 0
+	}
+	if !w.isBatch() {
+		if q.Remove(w) {
+			return 0
+		}
+		return 1
+	}
+	return int(w.need - cancelBatch(&q.counters, w, waiterCancelled))
+}
+
+// Reap 回收所有已过截止时间的等待者，返回回收数量（节点由 TryDequeue 延迟弹出）
+func (q *PriorityQueue[T]) Reap() int {
+	now := time.Now().UnixNano()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	reaped := 0
+	q.counters.nextDeadline.Store(0)
+	for _, it := range q.items {
+		if reap(&q.counters, it.w, now) {
+			reaped++
+		} else if it.w.pending() {
+			track(&q.counters, it.w)
+		}
+	}
+	return reaped
+}
+
+// Len 返回仍在等待的等待者数量（近似值），批量等待者只算一个，
+// 已取消、已过期的不计入：有等待者到期时先 Reap
+func (q *PriorityQueue[T]) Len() int {
+	if q.counters.due() {
+		q.Reap()
+	}
+	return int(q.counters.length.Load())
+}
+
+// Demand 返回所有等待者还差的资源总数（近似值）
+func (q *PriorityQueue[T]) Demand() int64 {
+	if q.counters.due() {
+		q.Reap()
+	}
+	return q.counters.demand.Load()
+}
+
+// Clear 清空队列，关闭普通等待者的 channel 通知池已关闭
+func (q *PriorityQueue[T]) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pinned = nil
+	for len(q.items) > 0 {
+		w := heap.Pop(&q.items).(*pqItem[T]).w
+		if w.isBatch() {
+			cancelBatch(&q.counters, w, waiterClosed)
+			continue
+		}
+		if retire(&q.counters, w, waiterClosed) {
+			close(w.Ch)
+		}
+	}
+}
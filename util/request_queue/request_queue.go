@@ -4,20 +4,14 @@ import (
 	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
-type LockFreeWaiter[T any] struct {
-	Ch        chan T
-	next      unsafe.Pointer
-	Cancelled atomic.Bool
-	delivered atomic.Bool // 新增：资源已投递，Ch 可能还没被读
-}
-
 type LockFreeQueue[T any] struct {
 	head     unsafe.Pointer
 	tail     unsafe.Pointer
-	length   atomic.Int64
+	counters waitCounters
 	chanPool sync.Pool
 }
 
@@ -34,11 +28,33 @@ func NewLockFreeQueue[T any]() *LockFreeQueue[T] {
 
 // Enqueue 入队（非阻塞）
 func (q *LockFreeQueue[T]) Enqueue() *LockFreeWaiter[T] {
-	w := &LockFreeWaiter[T]{
-		Ch: q.chanPool.Get().(chan T),
+	return q.EnqueueWith(EnqueueOptions{})
+}
+
+// EnqueueN 以单个节点入队一个需要 n 个资源的批量等待者
+// Ch 容量为 n，资源按 FIFO 顺序逐个投递，凑满 n 个后节点出队
+// 批量等待者必须用 RemoveBatch 取消
+func (q *LockFreeQueue[T]) EnqueueN(n int) *LockFreeWaiter[T] {
+	return q.EnqueueWith(EnqueueOptions{N: n})
+}
+
+// EnqueueWith 按参数入队，FIFO 队列忽略 Priority
+func (q *LockFreeQueue[T]) EnqueueWith(opts EnqueueOptions) *LockFreeWaiter[T] {
+	var ch chan T
+	if opts.N > 1 {
+		ch = make(chan T, opts.N)
+	} else {
+		ch = q.chanPool.Get().(chan T)
 	}
-	w.Cancelled.Store(false)
+	w := newWaiter(ch, opts)
+	q.link(w)
+	return w
+}
 
+// link 把节点挂到队尾
+func (q *LockFreeQueue[T]) link(w *LockFreeWaiter[T]) {
+	// 先计数再链接：节点一旦可见就可能被投递并扣减计数
+	admit(&q.counters, w)
 	for {
 		tail := (*LockFreeWaiter[T])(atomic.LoadPointer(&q.tail))
 		next := (*LockFreeWaiter[T])(atomic.LoadPointer(&tail.next))
@@ -50,8 +66,7 @@ func (q *LockFreeQueue[T]) Enqueue() *LockFreeWaiter[T] {
 				if atomic.CompareAndSwapPointer(&tail.next, nil, unsafe.Pointer(w)) {
 					// 成功链接，尝试更新 tail（允许失败）
 					atomic.CompareAndSwapPointer(&q.tail, unsafe.Pointer(tail), unsafe.Pointer(w))
-					q.length.Add(1)
-					return w
+					return
 				}
 			} else {
 				// tail 落后，帮助推进
@@ -63,9 +78,11 @@ func (q *LockFreeQueue[T]) Enqueue() *LockFreeWaiter[T] {
 }
 
 // TryDequeue 尝试将资源分发给队列中的第一个有效等待者（优化版）
+// 已取消、已过截止时间的等待者会被跳过并摘除，不会收到资源
 func (q *LockFreeQueue[T]) TryDequeue(resource T) bool {
 	maxAttempts := 100 // 最多尝试100次，避免无限循环
 	attempts := 0
+	var now int64 // 懒取当前时间，只有遇到带截止时间的等待者才调用 time.Now
 
 	for attempts < maxAttempts {
 		attempts++
@@ -90,31 +107,45 @@ func (q *LockFreeQueue[T]) TryDequeue(resource T) bool {
 			continue
 		}
 
-		// ============ 处理已取消的节点（延迟删除）============
-		if next.Cancelled.Load() {
-			if atomic.CompareAndSwapPointer(&q.head, unsafe.Pointer(head), unsafe.Pointer(next)) {
-				q.length.Add(-1)
-				q.recycle(next.Ch)
+		// ============ 处理已取消 / 已完成的节点（延迟删除）============
+		if !next.pending() {
+			q.unlink(head, next)
+			// 跳过该节点，继续寻找下一个
+			continue
+		}
+
+		// ============ 截止时间已过：回收而不投递 ============
+		if next.deadline != 0 {
+			if now == 0 {
+				now = time.Now().UnixNano()
+			}
+			if reap(&q.counters, next, now) {
+				q.unlink(head, next)
+				continue
+			}
+		}
+
+		// ============ 批量等待者：逐个投递，凑满后出队 ============
+		if next.isBatch() {
+			if deliverBatch(&q.counters, next, resource) {
+				if !next.pending() {
+					q.unlink(head, next)
+				}
+				return true
 			}
-			// 跳过已取消的节点，继续寻找下一个
 			continue
 		}
 
 		// ============ 尝试抢占等待者并分发资源 ============
 		if atomic.CompareAndSwapPointer(&q.head, unsafe.Pointer(head), unsafe.Pointer(next)) {
-			q.length.Add(-1)
-
-			// 尝试发送资源（非阻塞）
-			select {
-			case next.Ch <- resource:
-				// 成功分发资源
+			if retire(&q.counters, next, waiterDelivered) {
+				// 成功抢占：Ch 容量为 1 且只有抢占成功的一方写入，不会阻塞
+				next.Ch <- resource
 				return true
-			default:
-				// Channel 已满或关闭（理论上不应该发生）
-				// 回收 channel 并继续寻找下一个等待者
-				q.recycle(next.Ch)
-				return false
 			}
+			// 抢占期间被取消：资源不投递，回收 channel 后继续找下一个
+			q.recycleIfCancelled(next)
+			continue
 		}
 
 		// CAS 失败，有其他协程在操作，快速重试
@@ -128,6 +159,21 @@ func (q *LockFreeQueue[T]) TryDequeue(resource T) bool {
 	return false
 }
 
+// unlink 把 head 从 head 推进到 next，推进成功的一方负责回收 channel
+func (q *LockFreeQueue[T]) unlink(head, next *LockFreeWaiter[T]) {
+	if atomic.CompareAndSwapPointer(&q.head, unsafe.Pointer(head), unsafe.Pointer(next)) {
+		q.recycleIfCancelled(next)
+	}
+}
+
+// recycleIfCancelled 只回收被等待方自己 Remove 的普通等待者的 channel：
+// 已投递的 channel 归等待方所有，过期回收的等待方可能还在读，Clear 关闭的不能再复用
+func (q *LockFreeQueue[T]) recycleIfCancelled(w *LockFreeWaiter[T]) {
+	if !w.isBatch() && w.state.Load() == waiterCancelled {
+		q.recycle(w.Ch)
+	}
+}
+
 // recycle 回收 channel 到池中（同步回收）
 // channel 容量为 1，drain + sync.Pool.Put 都是 O(1)，不需要异步
 func (q *LockFreeQueue[T]) recycle(ch chan T) {
@@ -138,17 +184,67 @@ func (q *LockFreeQueue[T]) recycle(ch chan T) {
 	q.chanPool.Put(ch)
 }
 
-// Remove 标记节点为已取消（延迟删除）
-func (q *LockFreeQueue[T]) Remove(w *LockFreeWaiter[T]) {
-	if w != nil && w.Cancelled.CompareAndSwap(false, true) {
-		// 只标记为取消，物理删除由 TryDequeue 延迟完成
-		// 这样可以避免复杂的并发删除逻辑
+// Remove 取消等待者（延迟删除，物理删除由 TryDequeue 完成）
+// 返回 true 表示资源没有投递给它；返回 false 表示资源已投递或正在写入 Ch，
+// 调用方需要从 Ch 取出并自行归还
+func (q *LockFreeQueue[T]) Remove(w *LockFreeWaiter[T]) bool {
+	if w == nil {
+		return true
+	}
+	if w.isBatch() {
+		q.RemoveBatch(w)
+		return true
+	}
+	return remove(&q.counters, w)
+}
+
+// RemoveBatch 取消批量等待者，返回已被认领（已投递或即将写入 Ch）的资源数
+// 调用方需要从 Ch 中收齐这么多资源后自行归还
+func (q *LockFreeQueue[T]) RemoveBatch(w *LockFreeWaiter[T]) int {
+	if w == nil {
+		return 0
+	}
+	if !w.isBatch() {
+		if q.Remove(w) {
+			return 0
+		}
+		return 1
+	}
+	return int(w.need - cancelBatch(&q.counters, w, waiterCancelled))
+}
+
+// Reap 遍历队列，回收所有已过截止时间的等待者，返回回收数量
+// 只改状态不摘节点，摘除仍由 TryDequeue 延迟完成
+func (q *LockFreeQueue[T]) Reap() int {
+	now := time.Now().UnixNano()
+	reaped := 0
+	q.counters.nextDeadline.Store(0)
+	head := (*LockFreeWaiter[T])(atomic.LoadPointer(&q.head))
+	for w := (*LockFreeWaiter[T])(atomic.LoadPointer(&head.next)); w != nil; w = (*LockFreeWaiter[T])(atomic.LoadPointer(&w.next)) {
+		if reap(&q.counters, w, now) {
+			reaped++
+		} else if w.pending() {
+			track(&q.counters, w)
+		}
 	}
+	return reaped
 }
 
-// Len 返回队列长度（近似值）
+// Len 返回仍在等待的等待者数量（近似值），批量等待者只算一个，
+// 已取消、已过期的不计入：有等待者到期时先 Reap
 func (q *LockFreeQueue[T]) Len() int {
-	return int(q.length.Load())
+	if q.counters.due() {
+		q.Reap()
+	}
+	return int(q.counters.length.Load())
+}
+
+// Demand 返回所有等待者还差的资源总数（近似值），批量等待者按还差的数量计
+func (q *LockFreeQueue[T]) Demand() int64 {
+	if q.counters.due() {
+		q.Reap()
+	}
+	return q.counters.demand.Load()
 }
 
 // Clear 清空队列
@@ -161,12 +257,14 @@ func (q *LockFreeQueue[T]) Clear() {
 			return // 队列已空
 		}
 
-		if atomic.CompareAndSwapPointer(&q.head, unsafe.Pointer(head), unsafe.Pointer(next)) {
-			q.length.Add(-1)
+		if next.isBatch() {
+			// 批量等待者的 Ch 可能正被投递方写入，不能 close；
+			// 冻结 remaining 阻止后续投递，等待方通过自身的取消路径退出
+			cancelBatch(&q.counters, next, waiterClosed)
+		} else if retire(&q.counters, next, waiterClosed) {
 			// 关闭 channel 以通知等待者池已关闭
-			if next.Ch != nil {
-				close(next.Ch)
-			}
+			close(next.Ch)
 		}
+		atomic.CompareAndSwapPointer(&q.head, unsafe.Pointer(head), unsafe.Pointer(next))
 	}
 }
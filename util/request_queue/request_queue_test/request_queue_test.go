@@ -181,3 +181,285 @@ func TestLockFreeQueue_HighContention(t *testing.T) {
 		t.Errorf("Expected %d, got %d", numOps, receivedCount.Load())
 	}
 }
+
+// ============ 批量等待者测试 ============
+
+func TestLockFreeQueue_EnqueueN(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	wb := q.EnqueueN(3)
+	w := q.Enqueue()
+
+	if q.Len() != 2 {
+		t.Errorf("batch waiter should count as one node, got Len=%d", q.Len())
+	}
+	if q.Demand() != 4 {
+		t.Errorf("expected demand 4, got %d", q.Demand())
+	}
+
+	// 前 3 个资源都应该投递给批量等待者（FIFO），第 4 个给后面的普通等待者
+	for i := 1; i <= 4; i++ {
+		if !q.TryDequeue(i) {
+			t.Fatalf("TryDequeue(%d) failed", i)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		if v := <-wb.Ch; v != i {
+			t.Errorf("batch waiter expected %d, got %d", i, v)
+		}
+	}
+	if v := <-w.Ch; v != 4 {
+		t.Errorf("single waiter expected 4, got %d", v)
+	}
+	if q.Len() != 0 || q.Demand() != 0 {
+		t.Errorf("queue should be empty, Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+}
+
+func TestLockFreeQueue_RemoveBatch(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	wb := q.EnqueueN(3)
+	w := q.Enqueue()
+	q.TryDequeue(1) // 批量等待者认领 1 个
+
+	if claimed := q.RemoveBatch(wb); claimed != 1 {
+		t.Errorf("expected 1 claimed resource, got %d", claimed)
+	}
+	if v := <-wb.Ch; v != 1 {
+		t.Errorf("expected claimed resource 1, got %d", v)
+	}
+
+	// 取消后的批量等待者应被跳过
+	if !q.TryDequeue(2) {
+		t.Fatal("TryDequeue should skip cancelled batch waiter")
+	}
+	if v := <-w.Ch; v != 2 {
+		t.Errorf("single waiter expected 2, got %d", v)
+	}
+	if q.Demand() != 0 {
+		t.Errorf("expected demand 0, got %d", q.Demand())
+	}
+}
+
+// ============ 优先级队列测试 ============
+
+func TestPriorityQueue_Order(t *testing.T) {
+	q := NewPriorityQueue[int](0) // 关闭老化，严格按优先级
+
+	low1 := q.EnqueuePriority(0, 1)
+	high := q.EnqueuePriority(10, 1)
+	low2 := q.EnqueuePriority(0, 1)
+	mid := q.EnqueuePriority(5, 1)
+
+	for i := 1; i <= 4; i++ {
+		if !q.TryDequeue(i) {
+			t.Fatalf("TryDequeue(%d) failed", i)
+		}
+	}
+
+	// 高优先级先服务，同优先级 FIFO
+	for _, c := range []struct {
+		name string
+		w    *LockFreeWaiter[int]
+		want int
+	}{{"high", high, 1}, {"mid", mid, 2}, {"low1", low1, 3}, {"low2", low2, 4}} {
+		if v := <-c.w.Ch; v != c.want {
+			t.Errorf("%s expected %d, got %d", c.name, c.want, v)
+		}
+	}
+}
+
+func TestPriorityQueue_Aging(t *testing.T) {
+	q := NewPriorityQueue[int](10 * time.Millisecond)
+
+	old := q.EnqueuePriority(0, 1)
+	time.Sleep(50 * time.Millisecond) // 老化约 5 级
+	fresh := q.EnqueuePriority(2, 1)
+
+	q.TryDequeue(1)
+	select {
+	case v := <-old.Ch:
+		if v != 1 {
+			t.Errorf("aged waiter expected 1, got %d", v)
+		}
+	default:
+		t.Error("aged low-priority waiter should be served before a fresh priority-2 waiter")
+	}
+	q.Remove(fresh)
+}
+
+func TestPriorityQueue_RemoveAndClear(t *testing.T) {
+	q := NewPriorityQueue[int](0)
+
+	cancelled := q.EnqueuePriority(10, 1)
+	batch := q.EnqueuePriority(5, 2)
+	w := q.EnqueuePriority(0, 1)
+	q.Remove(cancelled)
+
+	if q.Demand() != 3 || q.Len() != 2 {
+		t.Errorf("cancelled waiter should not count, Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+	q.TryDequeue(1) // 跳过已取消的，交给批量等待者
+	if v := <-batch.Ch; v != 1 {
+		t.Errorf("batch waiter expected 1, got %d", v)
+	}
+	if claimed := q.RemoveBatch(batch); claimed != 1 {
+		t.Errorf("expected 1 claimed, got %d", claimed)
+	}
+
+	q.Clear()
+	if _, ok := <-w.Ch; ok {
+		t.Error("Clear should close single waiter channel")
+	}
+	if q.Len() != 0 || q.Demand() != 0 {
+		t.Errorf("queue should be empty, Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+}
+
+// 已收到部分资源的批量等待者凑满之前，后来的高优先级批量等待者不能插队
+func TestPriorityQueue_PartialBatchPinned(t *testing.T) {
+	q := NewPriorityQueue[int](0)
+
+	low := q.EnqueuePriority(0, 3)
+	q.TryDequeue(1)
+	q.TryDequeue(2)
+	high := q.EnqueuePriority(10, 3)
+	q.TryDequeue(3)
+	q.TryDequeue(4)
+
+	if len(low.Ch) != 3 {
+		t.Errorf("partially served batch should be filled first, got %d of 3", len(low.Ch))
+	}
+	if len(high.Ch) != 1 {
+		t.Errorf("high-priority batch expected 1 resource after low is full, got %d", len(high.Ch))
+	}
+	if q.Len() != 1 || q.Demand() != 2 {
+		t.Errorf("expected Len=1 Demand=2, got Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+
+	// 被钉住的批量等待者取消后，恢复按优先级服务
+	q2 := NewPriorityQueue[int](0)
+	pinned := q2.EnqueuePriority(0, 3)
+	q2.TryDequeue(1)
+	next := q2.EnqueuePriority(10, 1)
+	q2.RemoveBatch(pinned)
+	q2.TryDequeue(2)
+	if v := <-next.Ch; v != 2 {
+		t.Errorf("expected 2 after the pinned batch was cancelled, got %d", v)
+	}
+}
+
+// ============ 截止时间测试 ============
+
+func TestLockFreeQueue_SkipExpired(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	expired := q.EnqueueWith(EnqueueOptions{Deadline: time.Now().Add(10 * time.Millisecond)})
+	live := q.EnqueueWith(EnqueueOptions{Deadline: time.Now().Add(time.Hour)})
+	time.Sleep(20 * time.Millisecond)
+
+	if !q.TryDequeue(7) {
+		t.Fatal("TryDequeue should skip the expired waiter and deliver to the live one")
+	}
+	select {
+	case v := <-live.Ch:
+		if v != 7 {
+			t.Errorf("expected 7, got %d", v)
+		}
+	default:
+		t.Fatal("live waiter did not receive the resource")
+	}
+	// 过期等待者被回收而不是投递：Remove 返回 true，表示不需要再从 Ch 取资源
+	if !q.Remove(expired) {
+		t.Error("expired waiter should not have been delivered to")
+	}
+	if q.Len() != 0 || q.Demand() != 0 {
+		t.Errorf("queue should be empty, Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+}
+
+func TestLockFreeQueue_ReapAndLen(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	for i := 0; i < 3; i++ {
+		q.EnqueueWith(EnqueueOptions{Deadline: time.Now().Add(5 * time.Millisecond)})
+	}
+	w := q.Enqueue()
+	cancelled := q.Enqueue()
+	q.Remove(cancelled)
+
+	if q.Len() != 4 {
+		t.Errorf("cancelled waiter should be excluded from Len, got %d", q.Len())
+	}
+	time.Sleep(10 * time.Millisecond)
+	// 到期后不需要等下一次 Reap，Len / Demand 就不再计入过期等待者
+	if q.Len() != 1 || q.Demand() != 1 {
+		t.Errorf("expected Len=1 Demand=1 once expired, got Len=%d Demand=%d", q.Len(), q.Demand())
+	}
+	if n := q.Reap(); n != 0 {
+		t.Errorf("expired waiters should already be reaped, got %d", n)
+	}
+
+	q.TryDequeue(1)
+	if v := <-w.Ch; v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+}
+
+func TestLockFreeQueue_RemoveAfterDelivery(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	w := q.Enqueue()
+	q.TryDequeue(42)
+	// 已投递后取消：Remove 返回 false，资源仍在 Ch 中等待调用方取回
+	if q.Remove(w) {
+		t.Fatal("Remove should report that the resource was already delivered")
+	}
+	if v := <-w.Ch; v != 42 {
+		t.Errorf("expected 42, got %d", v)
+	}
+}
+
+// 过期回收的等待者的 channel 不能被新的等待者复用：原等待方可能还在读它
+func TestLockFreeQueue_ExpiredChannelNotReused(t *testing.T) {
+	q := NewLockFreeQueue[int]()
+
+	expired := q.EnqueueWith(EnqueueOptions{Deadline: time.Now().Add(5 * time.Millisecond)})
+	time.Sleep(10 * time.Millisecond)
+	if q.TryDequeue(1) {
+		t.Fatal("expired waiter should not be delivered to")
+	}
+
+	for i := 0; i < 100; i++ {
+		w := q.Enqueue()
+		if w.Ch == expired.Ch {
+			t.Fatal("channel of an expired waiter was reused")
+		}
+		if !q.TryDequeue(i) {
+			t.Fatal("TryDequeue should deliver to the new waiter")
+		}
+		if v := <-w.Ch; v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+	}
+	if q.Remove(expired) != true || len(expired.Ch) != 0 {
+		t.Error("expired waiter should not hold any resource")
+	}
+}
+
+func TestPriorityQueue_SkipExpired(t *testing.T) {
+	q := NewPriorityQueue[int](0)
+
+	expired := q.EnqueueWith(EnqueueOptions{Priority: 10, Deadline: time.Now().Add(5 * time.Millisecond)})
+	live := q.EnqueueWith(EnqueueOptions{Priority: 0})
+	time.Sleep(10 * time.Millisecond)
+
+	q.TryDequeue(3)
+	if v := <-live.Ch; v != 3 {
+		t.Errorf("expected 3, got %d", v)
+	}
+	if !q.Remove(expired) {
+		t.Error("expired waiter should not have been delivered to")
+	}
+}
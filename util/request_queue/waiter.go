@@ -0,0 +1,213 @@
+package request_queue
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// 等待者状态：每个等待者只会从 waiterPending 转移一次
+// 转移成功的一方负责扣减 Len/Demand，也决定资源归属，避免投递与取消同时发生时资源丢失
+const (
+	waiterPending   int32 = iota
+	waiterDelivered       // 资源已投递（批量等待者：已凑满）
+	waiterCancelled       // Remove 取消
+	waiterExpired         // 超过截止时间被回收；等待方可能仍在读 Ch，channel 不能复用
+	waiterClosed          // Clear 关闭（Ch 已 close）
+)
+
+type LockFreeWaiter[T any] struct {
+	Ch        chan T
+	next      unsafe.Pointer
+	Cancelled atomic.Bool
+	state     atomic.Int32
+
+	// deadline 截止时间（UnixNano，0 表示不限），过期后 TryDequeue 跳过并回收
+	deadline int64
+
+	// 批量等待者（EnqueueN 创建）：一个节点需要 need 个资源
+	// remaining 为还差的数量，投递方 CAS 递减后再写 Ch，减到 0 时节点出队
+	need      int64
+	remaining atomic.Int64
+}
+
+// EnqueueOptions 入队参数
+type EnqueueOptions struct {
+	N        int       // 需要的资源数，<=1 为普通等待者
+	Priority int       // 优先级，数值越大越先服务（仅 PriorityQueue 使用）
+	Deadline time.Time // 截止时间，零值表示不限
+}
+
+// WaitQueue 等待队列的公共契约，池子通过它在 FIFO / 优先级队列之间切换
+type WaitQueue[T any] interface {
+	Enqueue() *LockFreeWaiter[T]
+	EnqueueN(n int) *LockFreeWaiter[T]
+	EnqueueWith(opts EnqueueOptions) *LockFreeWaiter[T]
+	TryDequeue(resource T) bool
+	Remove(w *LockFreeWaiter[T]) bool
+	RemoveBatch(w *LockFreeWaiter[T]) int
+	Reap() int
+	Len() int
+	Demand() int64
+	Clear()
+}
+
+func newWaiter[T any](ch chan T, opts EnqueueOptions) *LockFreeWaiter[T] {
+	w := &LockFreeWaiter[T]{Ch: ch}
+	if opts.N > 1 {
+		w.need = int64(opts.N)
+		w.remaining.Store(int64(opts.N))
+	}
+	if !opts.Deadline.IsZero() {
+		w.deadline = opts.Deadline.UnixNano()
+	}
+	return w
+}
+
+// Need 返回该等待者需要的资源数（普通等待者为 1）
+func (w *LockFreeWaiter[T]) Need() int64 {
+	if w.need < 1 {
+		return 1
+	}
+	return w.need
+}
+
+// Deadline 返回等待者的截止时间，未设置时 ok=false
+func (w *LockFreeWaiter[T]) Deadline() (deadline time.Time, ok bool) {
+	if w.deadline == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, w.deadline), true
+}
+
+func (w *LockFreeWaiter[T]) isBatch() bool {
+	return w.need > 1
+}
+
+func (w *LockFreeWaiter[T]) pending() bool {
+	return w.state.Load() == waiterPending
+}
+
+func (w *LockFreeWaiter[T]) expired(now int64) bool {
+	return w.deadline != 0 && now >= w.deadline
+}
+
+// waitCounters 两种队列共用的 Len / Demand 计数
+// Len 只统计仍在等待（pending）的节点，已取消、已过期的节点即使还没被物理摘除也不计入
+type waitCounters struct {
+	length atomic.Int64
+	demand atomic.Int64 // 所有等待者还差的资源总数（批量等待者按 remaining 计）
+
+	// nextDeadline 等待中节点最早的截止时间（UnixNano，0 表示没有），
+	// 到期后 Len / Demand 先 Reap 再返回，过期节点不会一直计入
+	nextDeadline atomic.Int64
+}
+
+func admit[T any](c *waitCounters, w *LockFreeWaiter[T]) {
+	c.length.Add(1)
+	c.demand.Add(w.Need())
+	track(c, w)
+}
+
+// track 把等待中节点的截止时间并入 nextDeadline
+func track[T any](c *waitCounters, w *LockFreeWaiter[T]) {
+	d := w.deadline
+	if d == 0 {
+		return
+	}
+	for {
+		cur := c.nextDeadline.Load()
+		if cur != 0 && cur <= d {
+			return
+		}
+		if c.nextDeadline.CompareAndSwap(cur, d) {
+			return
+		}
+	}
+}
+
+// due 是否有等待者可能已过期，需要先 Reap
+func (c *waitCounters) due() bool {
+	d := c.nextDeadline.Load()
+	return d != 0 && time.Now().UnixNano() >= d
+}
+
+// retire 把等待者从 pending 转到 to，成功的一方扣减计数
+// 普通等待者在这里扣减 Demand；批量等待者的 Demand 由投递和 cancelBatch 扣减
+func retire[T any](c *waitCounters, w *LockFreeWaiter[T], to int32) bool {
+	if !w.state.CompareAndSwap(waiterPending, to) {
+		return false
+	}
+	c.length.Add(-1)
+	if !w.isBatch() {
+		c.demand.Add(-1)
+	}
+	if to != waiterDelivered {
+		w.Cancelled.Store(true)
+	}
+	return true
+}
+
+// batchCancelled 标记在 remaining 的高位：置位后 remaining 冻结，不再接受投递
+// 这样取消方能准确知道已被认领的资源数 = need - 冻结时的 remaining
+const batchCancelled = int64(1) << 62
+
+// cancelBatch 冻结批量等待者的 remaining 并置为 to 状态，返回冻结时还差的数量
+func cancelBatch[T any](c *waitCounters, w *LockFreeWaiter[T], to int32) int64 {
+	var left int64
+	for {
+		st := w.remaining.Load()
+		if st&batchCancelled != 0 {
+			left = st &^ batchCancelled
+			break
+		}
+		if w.remaining.CompareAndSwap(st, st|batchCancelled) {
+			c.demand.Add(-st)
+			left = st
+			break
+		}
+	}
+	retire(c, w, to)
+	return left
+}
+
+// deliverBatch 向批量等待者投递一个资源，返回是否投递成功
+// 凑满最后一个时把等待者置为 delivered
+func deliverBatch[T any](c *waitCounters, w *LockFreeWaiter[T], resource T) bool {
+	st := w.remaining.Load()
+	if st&batchCancelled != 0 || st <= 0 {
+		return false
+	}
+	if !w.remaining.CompareAndSwap(st, st-1) {
+		return false
+	}
+	c.demand.Add(-1)
+	// Ch 容量为 need，成功 CAS 的投递次数不会超过 need，这里不会阻塞
+	w.Ch <- resource
+	if st-1 == 0 {
+		retire(c, w, waiterDelivered)
+	}
+	return true
+}
+
+// reap 回收已过期的等待者，返回是否回收
+// 置为 waiterExpired 而不是 waiterCancelled：等待方还没察觉，channel 不能回收复用
+func reap[T any](c *waitCounters, w *LockFreeWaiter[T], now int64) bool {
+	if !w.pending() || !w.expired(now) {
+		return false
+	}
+	if w.isBatch() {
+		cancelBatch(c, w, waiterExpired)
+		return true
+	}
+	return retire(c, w, waiterExpired)
+}
+
+// remove 取消普通等待者
+// 返回 true 表示资源未投递给它；false 表示资源已投递（或正在写入 Ch），调用方需要从 Ch 取出并归还
+func remove[T any](c *waitCounters, w *LockFreeWaiter[T]) bool {
+	if retire(c, w, waiterCancelled) {
+		return true
+	}
+	return w.state.Load() != waiterDelivered
+}